@@ -6,14 +6,25 @@ import (
 	"time"
 )
 
+const (
+	defaultTimeFormatZone = "2006-01-02T15:04:05.999+07:00"
+	defaultTimeFormatUTC  = "2006-01-02T15:04:05.999Z"
+)
+
 var (
 	// DefaultTimePrintFormat is the default time format: UTC, Local, Ago
 	DefaultTimePrintFormat = "UTC"
 
-	// DefaultTimeFormatZone is the default time format
-	DefaultTimeFormatZone = "2006-01-02T15:04:05.999+07:00"
-	// DefaultTimeFormatUTC is the default time format
-	DefaultTimeFormatUTC = "2006-01-02T15:04:05.999Z"
+	// DefaultTimeFormatZone is the default time format.
+	// When changed, DefaultTimeParser tries it before DefaultTimeLayouts.
+	//
+	// Deprecated: use DefaultTimeLayouts or TimeParser.Layouts
+	DefaultTimeFormatZone = defaultTimeFormatZone
+	// DefaultTimeFormatUTC is the default time format.
+	// When changed, DefaultTimeParser tries it before DefaultTimeLayouts.
+	//
+	// Deprecated: use DefaultTimeLayouts or TimeParser.Layouts
+	DefaultTimeFormatUTC = defaultTimeFormatUTC
 
 	// DefaultTimeTruncate is the default time to truncate as Postgres time precision is default to 6
 	// However, JavaScript and AWS accept time milliseconds only, 3 digits, so we truncate to 3
@@ -22,46 +33,21 @@ var (
 	NowFunc = time.Now
 )
 
-// ParseStringTime returns Time from a string
+// ParseStringTime returns Time from a string, truncated to DefaultTimeTruncate,
+// or zero time if the string can not be parsed, see TimeParser
 func ParseStringTime(val string) time.Time {
-	if val == "" {
-		return time.Time{}
-	}
-
-	var t time.Time
-	switch len(val) {
-	case len(DefaultTimeFormatZone):
-		t, _ = time.Parse(DefaultTimeFormatZone, val)
-	case len(DefaultTimeFormatUTC):
-		t, _ = time.Parse(DefaultTimeFormatUTC, val)
-	case len(time.RFC3339):
-		t, _ = time.Parse(time.RFC3339, val)
-	case len(time.DateTime):
-		t, _ = time.Parse(time.DateTime, val)
-	case len(time.DateOnly):
-		t, _ = time.Parse(time.DateOnly, val)
-	default:
-		t, _ = time.Parse(time.RFC3339Nano, val)
-	}
+	t, _ := DefaultTimeParser.ParseString(val)
 	return t.Truncate(DefaultTimeTruncate)
 }
 
-// ParseTime returns time from any type
+// ParseTime returns time from any type,
+// or zero time if the value can not be parsed, see TimeParser
 func ParseTime(val any) time.Time {
-	switch t := val.(type) {
-	case int64:
-		return time.Unix(t, 0)
-	case string:
-		return ParseStringTime(t)
-	case time.Time:
-		return t
-	case *time.Time:
-		if t == nil {
-			return time.Time{}
-		}
-		return *t
+	if s, ok := val.(string); ok {
+		return ParseStringTime(s)
 	}
-	return time.Time{}
+	t, _ := DefaultTimeParser.Parse(val)
+	return t
 }
 
 const timeFormat = "2006-01-02 15:04:05"
@@ -76,7 +62,9 @@ func Time(val any) string {
 
 	switch t := val.(type) {
 	case int64:
-		return time.Unix(t, 0).UTC().Format(timeFormat)
+		// the unit is detected as in ParseTime
+		tm, _ := DefaultTimeParser.Parse(t)
+		return tm.UTC().Format(timeFormat)
 	case string:
 		return t
 	case *time.Time:
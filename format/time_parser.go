@@ -0,0 +1,257 @@
+package format
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// DefaultTimeLayouts are the layouts tried in order by TimeParser
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05-0700",
+	time.DateTime,
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.ANSIC,
+	time.UnixDate,
+}
+
+// TimeParser parses time from strings, Unix times and time values.
+//
+// Strings are parsed with the layouts, then as Unix time if numeric,
+// then as ISO week date: 2024-W03 or 2024-W03-2.
+// Numbers are Unix times in seconds, milliseconds, microseconds
+// or nanoseconds, see EpochUnit.
+type TimeParser struct {
+	// Layouts to parse strings, DefaultTimeLayouts when empty,
+	// preceded by DefaultTimeFormatZone and DefaultTimeFormatUTC if changed
+	Layouts []string
+	// Location is used for layouts and ISO weeks without time zone, UTC by default,
+	// and for Unix times, Local by default
+	Location *time.Location
+	// EpochUnit is the unit of Unix times: time.Second, time.Millisecond,
+	// time.Microsecond or time.Nanosecond.
+	// When zero, the unit is detected by the magnitude of the value:
+	// below 1e11 are seconds (up to year 5138), below 1e14 milliseconds,
+	// below 1e17 microseconds, and nanoseconds otherwise.
+	EpochUnit time.Duration
+}
+
+// DefaultTimeParser is used by ParseTime and ParseStringTime
+var DefaultTimeParser = TimeParser{}
+
+// Parse returns time from a string, number, json.Number, time.Time or *time.Time.
+// Nil and empty string return zero time.
+func (p TimeParser) Parse(val any) (time.Time, error) {
+	switch t := val.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return t, nil
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, nil
+		}
+		return *t, nil
+	case string:
+		return p.ParseString(t)
+	case json.Number:
+		return p.ParseString(string(t))
+	case int:
+		return p.epoch(int64(t), 0)
+	case int8:
+		return p.epoch(int64(t), 0)
+	case int16:
+		return p.epoch(int64(t), 0)
+	case int32:
+		return p.epoch(int64(t), 0)
+	case int64:
+		return p.epoch(t, 0)
+	case uint:
+		return p.unsignedEpoch(uint64(t))
+	case uint8:
+		return p.epoch(int64(t), 0)
+	case uint16:
+		return p.epoch(int64(t), 0)
+	case uint32:
+		return p.epoch(int64(t), 0)
+	case uint64:
+		return p.unsignedEpoch(t)
+	case float32:
+		return p.floatEpoch(float64(t))
+	case float64:
+		return p.floatEpoch(t)
+	}
+	return time.Time{}, errors.Errorf("unsupported time type: %T", val)
+}
+
+// ParseString returns time from a string, see TimeParser.
+// Empty string returns zero time.
+func (p TimeParser) ParseString(val string) (time.Time, error) {
+	if val == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range p.layouts() {
+		if t, err := time.ParseInLocation(layout, val, p.location()); err == nil {
+			return t, nil
+		}
+	}
+
+	if v, frac, ok := splitNumber(val); ok {
+		return p.epoch(v, frac)
+	}
+	if t, ok, err := p.parseISOWeek(val); ok {
+		return t, err
+	}
+	return time.Time{}, errors.Errorf("unable to parse time %q", val)
+}
+
+// layouts returns Layouts, or DefaultTimeLayouts preceded by
+// DefaultTimeFormatZone and DefaultTimeFormatUTC if they were changed
+func (p TimeParser) layouts() []string {
+	if len(p.Layouts) > 0 {
+		return p.Layouts
+	}
+	var custom []string
+	if DefaultTimeFormatZone != "" && DefaultTimeFormatZone != defaultTimeFormatZone {
+		custom = append(custom, DefaultTimeFormatZone)
+	}
+	if DefaultTimeFormatUTC != "" && DefaultTimeFormatUTC != defaultTimeFormatUTC {
+		custom = append(custom, DefaultTimeFormatUTC)
+	}
+	if len(custom) == 0 {
+		return DefaultTimeLayouts
+	}
+	return append(custom, DefaultTimeLayouts...)
+}
+
+func (p TimeParser) location() *time.Location {
+	if p.Location == nil {
+		return time.UTC
+	}
+	return p.Location
+}
+
+// unit returns the unit of Unix time v
+func (p TimeParser) unit(v int64) time.Duration {
+	if p.EpochUnit != 0 {
+		return p.EpochUnit
+	}
+	if v < 0 {
+		v = -v
+	}
+	switch {
+	case v < 1e11:
+		return time.Second
+	case v < 1e14:
+		return time.Millisecond
+	case v < 1e17:
+		return time.Microsecond
+	default:
+		return time.Nanosecond
+	}
+}
+
+// epoch returns Unix time v with a fraction of the unit
+func (p TimeParser) epoch(v int64, frac float64) (time.Time, error) {
+	unit := p.unit(v)
+	switch unit {
+	case time.Second, time.Millisecond, time.Microsecond, time.Nanosecond:
+	default:
+		return time.Time{}, errors.Errorf("invalid epoch unit: %s", unit)
+	}
+
+	perSecond := int64(time.Second / unit)
+	t := time.Unix(v/perSecond, (v%perSecond)*int64(unit))
+	if frac != 0 {
+		t = t.Add(time.Duration(math.Round(frac * float64(unit))))
+	}
+	if p.Location != nil {
+		t = t.In(p.Location)
+	}
+	return t, nil
+}
+
+func (p TimeParser) unsignedEpoch(v uint64) (time.Time, error) {
+	if v > math.MaxInt64 {
+		return time.Time{}, errors.Errorf("unable to parse time %d: value out of range", v)
+	}
+	return p.epoch(int64(v), 0)
+}
+
+func (p TimeParser) floatEpoch(f float64) (time.Time, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) || math.Abs(f) >= math.MaxInt64 {
+		return time.Time{}, errors.Errorf("unable to parse time %v: value out of range", f)
+	}
+	v, frac := math.Modf(f)
+	return p.epoch(int64(v), frac)
+}
+
+// splitNumber splits a decimal number into the integer part and the signed fraction
+func splitNumber(s string) (v int64, frac float64, ok bool) {
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+	if hasFrac && fracPart == "" {
+		return 0, 0, false
+	}
+	v, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if hasFrac {
+		for _, c := range fracPart {
+			if c < '0' || c > '9' {
+				return 0, 0, false
+			}
+		}
+		frac, err = strconv.ParseFloat("0."+fracPart, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		if strings.HasPrefix(intPart, "-") {
+			frac = -frac
+		}
+	}
+	return v, frac, true
+}
+
+// parseISOWeek parses ISO 8601 week date: 2024-W03, 2024-W03-2, 2024W03 or 2024W032.
+// ok is false if the value is not in the week date form.
+func (p TimeParser) parseISOWeek(val string) (t time.Time, ok bool, err error) {
+	s := strings.ReplaceAll(val, "-", "")
+	if len(s) != 7 && len(s) != 8 || s[4] != 'W' {
+		return time.Time{}, false, nil
+	}
+	year, err1 := strconv.Atoi(s[:4])
+	week, err2 := strconv.Atoi(s[5:7])
+	day := 1
+	var err3 error
+	if len(s) == 8 {
+		day, err3 = strconv.Atoi(s[7:])
+	}
+	if err1 != nil || err2 != nil || err3 != nil {
+		return time.Time{}, false, nil
+	}
+	if day < 1 || day > 7 || week < 1 || week > 53 {
+		return time.Time{}, true, errors.Errorf("unable to parse time %q: invalid week date", val)
+	}
+
+	// week 1 contains January 4th
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, p.location())
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	t = monday.AddDate(0, 0, (week-1)*7+day-1)
+	if y, w := t.ISOWeek(); y != year || w != week {
+		return time.Time{}, true, errors.Errorf("unable to parse time %q: year %d has no week %d", val, year, week)
+	}
+	return t, true, nil
+}
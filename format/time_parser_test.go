@@ -0,0 +1,156 @@
+package format_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/effective-security/x/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeParser_Parse(t *testing.T) {
+	exp := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	p := format.TimeParser{Location: time.UTC}
+
+	tcases := []struct {
+		val any
+		exp time.Time
+	}{
+		{val: int64(1700000000), exp: exp},
+		{val: 1700000000, exp: exp},
+		{val: uint64(1700000000000), exp: exp},
+		{val: int64(1700000000000000), exp: exp},
+		{val: int64(1700000000000000000), exp: exp},
+		{val: 1700000000.5, exp: exp.Add(500 * time.Millisecond)},
+		{val: float64(1700000000123), exp: exp.Add(123 * time.Millisecond)},
+		{val: json.Number("1700000000"), exp: exp},
+		{val: "1700000000", exp: exp},
+		{val: "1700000000.000250", exp: exp.Add(250 * time.Microsecond)},
+		{val: "1700000000123", exp: exp.Add(123 * time.Millisecond)},
+		{val: "1700000000123456", exp: exp.Add(123456 * time.Microsecond)},
+		{val: "1700000000123456789", exp: exp.Add(123456789 * time.Nanosecond)},
+		{val: "-1.5", exp: time.Unix(-1, -500000000).UTC()},
+		{val: "2023-11-14T22:13:20Z", exp: exp},
+		{val: "2023-11-14T22:13:20.5Z", exp: exp.Add(500 * time.Millisecond)},
+		{val: "2023-11-15T01:13:20+03:00", exp: exp},
+		{val: "2023-11-15T01:13:20.000+0300", exp: exp},
+		{val: "2023-11-14T22:13:20", exp: exp},
+		{val: "2023-11-14 22:13:20", exp: exp},
+		{val: "2023-11-14 23:13:20+01:00", exp: exp},
+		{val: "Tue, 14 Nov 2023 22:13:20 GMT", exp: exp},
+		{val: "Tue, 14 Nov 2023 23:13:20 +0100", exp: exp},
+		{val: "Tuesday, 14-Nov-23 22:13:20 UTC", exp: exp},
+		{val: "Tue Nov 14 22:13:20 2023", exp: exp},
+		{val: "2023-11-14", exp: exp.Truncate(24 * time.Hour)},
+		{val: "2023-W46", exp: time.Date(2023, 11, 13, 0, 0, 0, 0, time.UTC)},
+		{val: "2023-W46-2", exp: time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC)},
+		{val: "2023W462", exp: time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC)},
+		{val: "2021-W01", exp: time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{val: "2020-W53-7", exp: time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{val: exp, exp: exp},
+		{val: &exp, exp: exp},
+		{val: nil},
+		{val: ""},
+		{val: (*time.Time)(nil)},
+	}
+
+	for _, tc := range tcases {
+		act, err := p.Parse(tc.val)
+		require.NoError(t, err, "%v", tc.val)
+		assert.True(t, tc.exp.Equal(act), "%v: expected %s, got %s", tc.val, tc.exp, act)
+	}
+}
+
+func TestTimeParser_Errors(t *testing.T) {
+	p := format.DefaultTimeParser
+
+	tcases := []struct {
+		val any
+		err string
+	}{
+		{val: "abc", err: `unable to parse time "abc"`},
+		{val: "2023-13-01", err: `unable to parse time "2023-13-01"`},
+		{val: "1700000000.", err: `unable to parse time "1700000000."`},
+		{val: "1.2.3", err: `unable to parse time "1.2.3"`},
+		{val: "99999999999999999999", err: `unable to parse time "99999999999999999999"`},
+		{val: "2023-W54", err: `unable to parse time "2023-W54": invalid week date`},
+		{val: "2023-W46-8", err: `unable to parse time "2023-W46-8": invalid week date`},
+		{val: "2021-W53", err: `unable to parse time "2021-W53": year 2021 has no week 53`},
+		{val: uint64(1 << 63), err: "unable to parse time 9223372036854775808: value out of range"},
+		{val: 1e300, err: "unable to parse time 1e+300: value out of range"},
+		{val: true, err: "unsupported time type: bool"},
+	}
+	for _, tc := range tcases {
+		_, err := p.Parse(tc.val)
+		assert.EqualError(t, err, tc.err)
+	}
+
+	_, err := format.TimeParser{EpochUnit: time.Minute}.Parse(1)
+	assert.EqualError(t, err, "invalid epoch unit: 1m0s")
+}
+
+func TestTimeParser_Options(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+
+	// explicit unit
+	p := format.TimeParser{EpochUnit: time.Millisecond}
+	act, err := p.Parse(1500)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1500), act.UnixMilli())
+
+	// explicit layouts, numeric strings are tried after layouts
+	p = format.TimeParser{Layouts: []string{"20060102", "02/01/2006 15:04"}, Location: loc}
+	act, err = p.Parse("20231114")
+	require.NoError(t, err)
+	assert.Equal(t, "2023-11-14T00:00:00-05:00", act.Format(time.RFC3339))
+
+	act, err = p.Parse("14/11/2023 10:30")
+	require.NoError(t, err)
+	assert.Equal(t, "2023-11-14T10:30:00-05:00", act.Format(time.RFC3339))
+
+	_, err = p.Parse("2023-11-14")
+	assert.EqualError(t, err, `unable to parse time "2023-11-14"`)
+
+	// Unix time in the location
+	act, err = p.Parse(1700000000)
+	require.NoError(t, err)
+	assert.Equal(t, loc, act.Location())
+	assert.Equal(t, "2023-11-14T17:13:20-05:00", act.Format(time.RFC3339))
+
+	// time zone in the value wins
+	p = format.TimeParser{Location: loc}
+	act, err = p.Parse("2023-11-14T10:00:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, "2023-11-14T10:00:00Z", act.Format(time.RFC3339))
+	act, err = p.Parse("2023-11-14T10:00:00")
+	require.NoError(t, err)
+	assert.Equal(t, "2023-11-14T10:00:00-05:00", act.Format(time.RFC3339))
+}
+
+func TestTimeParser_DeprecatedFormats(t *testing.T) {
+	zone, utc := format.DefaultTimeFormatZone, format.DefaultTimeFormatUTC
+	defer func() {
+		format.DefaultTimeFormatZone, format.DefaultTimeFormatUTC = zone, utc
+	}()
+
+	_, err := format.DefaultTimeParser.Parse("14.11.2023")
+	require.Error(t, err)
+
+	// changed formats are tried before DefaultTimeLayouts
+	format.DefaultTimeFormatUTC = "02.01.2006"
+	act, err := format.DefaultTimeParser.Parse("14.11.2023")
+	require.NoError(t, err)
+	assert.Equal(t, "2023-11-14T00:00:00Z", act.Format(time.RFC3339))
+	assert.Equal(t, act, format.ParseStringTime("14.11.2023"))
+
+	format.DefaultTimeFormatZone = "2006-01-02 15:04 MST"
+	act, err = format.DefaultTimeParser.Parse("2023-11-14 10:30 UTC")
+	require.NoError(t, err)
+	assert.Equal(t, "2023-11-14T10:30:00Z", act.Format(time.RFC3339))
+
+	// explicit layouts are used as-is
+	_, err = format.TimeParser{Layouts: []string{time.DateOnly}}.Parse("14.11.2023")
+	require.Error(t, err)
+}
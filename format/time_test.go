@@ -32,7 +32,7 @@ func TestParseStringTime(t *testing.T) {
 	t7 := format.ParseStringTime("2024-02-12T17:07:02.123Z")
 	assert.Equal(t, "2024-02-12T17:07:02Z", t7.UTC().Format(time.RFC3339))
 	t8 := format.ParseStringTime("2024-02-12T17:07:02.123+07:00")
-	assert.Equal(t, "2024-02-12T17:07:02Z", t8.UTC().Format(time.RFC3339))
+	assert.Equal(t, "2024-02-12T10:07:02Z", t8.UTC().Format(time.RFC3339))
 }
 
 func TestParseTime(t *testing.T) {
@@ -66,11 +66,14 @@ func TestFormatTime(t *testing.T) {
 	assert.Equal(t, "", format.Time(time.Time{}))
 	assert.Equal(t, "2023-01-01 12:00:00", format.Time(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)))
 	assert.Equal(t, "2023-01-01 12:00:00", format.Time(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC).Unix()))
+	assert.Equal(t, "2023-01-01 12:00:00", format.Time(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC).UnixMilli()))
+	assert.Equal(t, "2023-01-01 12:00:00", format.Time(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC).UnixNano()))
 
 	format.DefaultTimePrintFormat = "Local"
 	//	assert.Equal(t, "2023-01-01 12:00:00", format.Time("2023-01-01T12:00:00Z"))
 	assert.Equal(t, "2023-01-01 12:00:00", format.Time(time.Date(2023, 1, 1, 12, 0, 0, 0, time.Local)))
 	assert.Equal(t, "2023-01-01 12:00:00", format.Time(time.Date(2023, 1, 1, 12, 0, 0, 0, time.Local).Unix()))
+	assert.Equal(t, "2023-01-01 12:00:00", format.Time(time.Date(2023, 1, 1, 12, 0, 0, 0, time.Local).UnixMilli()))
 
 	format.NowFunc = func() time.Time { return time.Date(2024, 3, 4, 12, 59, 5, 0, time.UTC) }
 	format.DefaultTimePrintFormat = "Ago"
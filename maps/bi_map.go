@@ -0,0 +1,168 @@
+package maps
+
+import (
+	"encoding/json"
+	"iter"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ErrConflict is returned by BiMap when a key or a value is already mapped
+var ErrConflict = errors.New("mapping conflict")
+
+// BiMap is a bidirectional map with unique keys and unique values,
+// that supports lookup of the key by the value.
+// The zero value is an empty map ready to use.
+// BiMap is not safe for concurrent use.
+type BiMap[K, V comparable] struct {
+	forward map[K]V
+	inverse map[V]K
+}
+
+// NewBiMap returns an empty bidirectional map
+func NewBiMap[K, V comparable]() *BiMap[K, V] {
+	return &BiMap[K, V]{}
+}
+
+// Put maps the key to the value.
+// It returns ErrConflict if the key is mapped to another value,
+// or the value is mapped to another key.
+func (m *BiMap[K, V]) Put(key K, value V) error {
+	if err := m.check(key, value); err != nil {
+		return err
+	}
+	m.set(key, value)
+	return nil
+}
+
+// check returns ErrConflict if the key or the value is mapped to another one
+func (m *BiMap[K, V]) check(key K, value V) error {
+	if v, ok := m.forward[key]; ok && v != value {
+		return errors.Wrapf(ErrConflict, "cannot map %v to %v, key is mapped to %v", key, value, v)
+	}
+	if k, ok := m.inverse[value]; ok && k != key {
+		return errors.Wrapf(ErrConflict, "cannot map %v to %v, value is mapped to %v", key, value, k)
+	}
+	return nil
+}
+
+// ForcePut maps the key to the value,
+// removing the existing mappings of the key and the value
+func (m *BiMap[K, V]) ForcePut(key K, value V) {
+	m.DeleteKey(key)
+	m.DeleteValue(value)
+	m.set(key, value)
+}
+
+func (m *BiMap[K, V]) set(key K, value V) {
+	if m.forward == nil {
+		m.forward = make(map[K]V)
+		m.inverse = make(map[V]K)
+	}
+	m.forward[key] = value
+	m.inverse[value] = key
+}
+
+// Get returns the value for the key
+func (m *BiMap[K, V]) Get(key K) (V, bool) {
+	v, ok := m.forward[key]
+	return v, ok
+}
+
+// GetKey returns the key for the value
+func (m *BiMap[K, V]) GetKey(value V) (K, bool) {
+	k, ok := m.inverse[value]
+	return k, ok
+}
+
+// DeleteKey removes the key and its value, and returns true if the key was present
+func (m *BiMap[K, V]) DeleteKey(key K) bool {
+	v, ok := m.forward[key]
+	if ok {
+		delete(m.forward, key)
+		delete(m.inverse, v)
+	}
+	return ok
+}
+
+// DeleteValue removes the value and its key, and returns true if the value was present
+func (m *BiMap[K, V]) DeleteValue(value V) bool {
+	k, ok := m.inverse[value]
+	if ok {
+		delete(m.inverse, value)
+		delete(m.forward, k)
+	}
+	return ok
+}
+
+// Len returns the number of entries
+func (m *BiMap[K, V]) Len() int {
+	return len(m.forward)
+}
+
+// Keys returns the keys in random order
+func (m *BiMap[K, V]) Keys() []K {
+	return Keys(m.forward)
+}
+
+// Values returns the values in random order
+func (m *BiMap[K, V]) Values() []V {
+	return Keys(m.inverse)
+}
+
+// Clear removes all entries
+func (m *BiMap[K, V]) Clear() {
+	m.forward = nil
+	m.inverse = nil
+}
+
+// Inverse returns a copy of the map with keys and values swapped
+func (m *BiMap[K, V]) Inverse() *BiMap[V, K] {
+	res := NewBiMap[V, K]()
+	for k, v := range m.forward {
+		res.set(v, k)
+	}
+	return res
+}
+
+// All returns an iterator over key-value pairs in random order
+func (m *BiMap[K, V]) All() iter.Seq2[K, V] {
+	return Seq(m.forward)
+}
+
+// MarshalJSON implements json.Marshaler, the map is encoded as an object
+func (m BiMap[K, V]) MarshalJSON() ([]byte, error) {
+	if m.forward == nil {
+		return []byte("{}"), nil
+	}
+	b, err := json.Marshal(m.forward)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, and puts the decoded entries.
+// Existing entries are kept. ErrConflict is returned for duplicate values,
+// or entries conflicting with the existing ones, and then the map is not modified.
+func (m *BiMap[K, V]) UnmarshalJSON(data []byte) error {
+	var entries map[K]V
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return errors.WithStack(err)
+	}
+
+	decoded := NewBiMap[K, V]()
+	for k, v := range entries {
+		if err := decoded.check(k, v); err != nil {
+			return err
+		}
+		if err := m.check(k, v); err != nil {
+			return err
+		}
+		decoded.set(k, v)
+	}
+	for k, v := range decoded.forward {
+		m.set(k, v)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package maps_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/effective-security/x/maps"
+)
+
+func TestBiMap(t *testing.T) {
+	var m maps.BiMap[string, int]
+	require.NoError(t, m.Put("a", 1))
+	require.NoError(t, m.Put("b", 2))
+	require.NoError(t, m.Put("a", 1))
+
+	err := m.Put("a", 3)
+	require.ErrorIs(t, err, maps.ErrConflict)
+	assert.EqualError(t, err, "cannot map a to 3, key is mapped to 1: mapping conflict")
+	err = m.Put("c", 2)
+	require.ErrorIs(t, err, maps.ErrConflict)
+	assert.EqualError(t, err, "cannot map c to 2, value is mapped to b: mapping conflict")
+
+	v, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	k, ok := m.GetKey(2)
+	assert.True(t, ok)
+	assert.Equal(t, "b", k)
+	_, ok = m.GetKey(3)
+	assert.False(t, ok)
+
+	// "a" -> 2 replaces both "a" -> 1 and "b" -> 2
+	m.ForcePut("a", 2)
+	assert.Equal(t, 1, m.Len())
+	_, ok = m.Get("b")
+	assert.False(t, ok)
+	_, ok = m.GetKey(1)
+	assert.False(t, ok)
+
+	require.NoError(t, m.Put("b", 1))
+	assert.ElementsMatch(t, []string{"a", "b"}, m.Keys())
+	assert.ElementsMatch(t, []int{1, 2}, m.Values())
+	assert.Equal(t, map[string]int{"a": 2, "b": 1}, maps.Collect(m.All()))
+
+	inv := m.Inverse()
+	k, ok = inv.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, "a", k)
+
+	assert.True(t, m.DeleteKey("a"))
+	assert.False(t, m.DeleteKey("a"))
+	assert.True(t, m.DeleteValue(1))
+	assert.False(t, m.DeleteValue(1))
+	assert.Equal(t, 0, m.Len())
+	// inverse is a copy
+	assert.Equal(t, 2, inv.Len())
+
+	inv.Clear()
+	assert.Equal(t, 0, inv.Len())
+}
+
+func TestBiMapJSON(t *testing.T) {
+	var empty maps.BiMap[string, int]
+	b, err := json.Marshal(&empty)
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(b))
+
+	m := maps.NewBiMap[string, int]()
+	require.NoError(t, m.Put("b", 2))
+	require.NoError(t, m.Put("a", 1))
+	b, err = json.Marshal(m)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1,"b":2}`, string(b))
+
+	var m2 maps.BiMap[string, int]
+	require.NoError(t, json.Unmarshal(b, &m2))
+	k, ok := m2.GetKey(2)
+	assert.True(t, ok)
+	assert.Equal(t, "b", k)
+
+	err = json.Unmarshal([]byte(`{"a":1,"b":1}`), maps.NewBiMap[string, int]())
+	assert.ErrorIs(t, err, maps.ErrConflict)
+	assert.Error(t, json.Unmarshal([]byte(`[]`), &m2))
+}
+
+func TestBiMapJSON_NoPartialUpdate(t *testing.T) {
+	m := maps.NewBiMap[string, int]()
+	require.NoError(t, m.Put("x", 100))
+
+	for range 20 {
+		err := json.Unmarshal([]byte(`{"a":1,"b":2,"c":3,"d":1}`), m)
+		require.ErrorIs(t, err, maps.ErrConflict)
+		assert.Equal(t, map[string]int{"x": 100}, maps.Collect(m.All()))
+
+		err = json.Unmarshal([]byte(`{"a":1,"b":2,"c":100}`), m)
+		require.ErrorIs(t, err, maps.ErrConflict)
+		assert.Equal(t, map[string]int{"x": 100}, maps.Collect(m.All()))
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"a":1,"x":100}`), m))
+	assert.Equal(t, map[string]int{"a": 1, "x": 100}, maps.Collect(m.All()))
+}
+
+func TestBiMapJSON_ValueField(t *testing.T) {
+	type config struct {
+		Codes maps.BiMap[string, int] `json:"codes"`
+	}
+	var c config
+	require.NoError(t, c.Codes.Put("ok", 200))
+
+	b, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Equal(t, `{"codes":{"ok":200}}`, string(b))
+
+	var c2 config
+	require.NoError(t, json.Unmarshal(b, &c2))
+	k, ok := c2.Codes.GetKey(200)
+	assert.True(t, ok)
+	assert.Equal(t, "ok", k)
+}
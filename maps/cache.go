@@ -0,0 +1,358 @@
+package maps
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// EvictionReason specifies why an entry was removed from Cache
+type EvictionReason int
+
+const (
+	// EvictionCapacity is reported when the least recently used entry is evicted
+	// to keep the cache within MaxSize
+	EvictionCapacity EvictionReason = iota + 1
+	// EvictionExpired is reported when the entry TTL has passed
+	EvictionExpired
+	// EvictionDeleted is reported when the entry is removed by Delete or Clear
+	EvictionDeleted
+)
+
+// String returns the reason name
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionCapacity:
+		return "capacity"
+	case EvictionExpired:
+		return "expired"
+	case EvictionDeleted:
+		return "deleted"
+	}
+	return "unknown"
+}
+
+// CacheOptions configures Cache
+type CacheOptions[K comparable, V any] struct {
+	// MaxSize is the maximum number of entries,
+	// the least recently used entries are evicted first.
+	// Zero means no limit.
+	MaxSize int
+	// TTL is the time to live of entries added by Set and GetOrLoad.
+	// Zero means the entries do not expire.
+	TTL time.Duration
+	// Loader returns the value for a missing key in GetOrLoad
+	Loader func(ctx context.Context, key K) (V, error)
+	// OnEvict is called after an entry is removed from the cache,
+	// it is not called when a value is replaced by Set
+	OnEvict func(key K, value V, reason EvictionReason)
+	// Now returns the current time, time.Now by default
+	Now func() time.Time
+}
+
+// CacheStats provides Cache statistics
+type CacheStats struct {
+	Hits       uint64
+	Misses     uint64
+	Loads      uint64
+	LoadErrors uint64
+	// Evictions is the number of entries evicted by capacity or expiration
+	Evictions uint64
+}
+
+// HitRatio returns the ratio of hits to all lookups
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Cache is a concurrency-safe LRU cache with optional per-entry TTL,
+// and loading of missing values with de-duplication of concurrent loads.
+type Cache[K comparable, V any] struct {
+	opts    CacheOptions[K, V]
+	lock    sync.Mutex
+	entries OrderedMap[K, *cacheEntry[V]]
+	calls   map[K]*cacheCall[V]
+	stats   CacheStats
+}
+
+type cacheEntry[V any] struct {
+	value V
+	// expires is zero if the entry does not expire
+	expires time.Time
+}
+
+type cacheCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// stale is set when the key is changed during the load,
+	// then the loaded value is not cached
+	stale bool
+}
+
+type evicted[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictionReason
+}
+
+// NewCache returns a new cache
+func NewCache[K comparable, V any](opts CacheOptions[K, V]) *Cache[K, V] {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Cache[K, V]{
+		opts:  opts,
+		calls: make(map[K]*cacheCall[V]),
+	}
+}
+
+// Get returns the value for the key, and marks it as recently used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.lock.Lock()
+	v, ok, ev := c.get(key)
+	if ok {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	c.lock.Unlock()
+
+	c.notify(ev)
+	return v, ok
+}
+
+// Set adds or replaces the value for the key with the default TTL
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.opts.TTL)
+}
+
+// SetWithTTL adds or replaces the value for the key with the ttl,
+// zero ttl means the entry does not expire
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.lock.Lock()
+	c.invalidate(key)
+	ev := c.set(key, value, ttl)
+	c.lock.Unlock()
+
+	c.notify(ev)
+}
+
+// Delete removes the key, and returns true if the key was present
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.lock.Lock()
+	c.invalidate(key)
+	e, ok := c.entries.Get(key)
+	if ok {
+		c.entries.Delete(key)
+	}
+	c.lock.Unlock()
+
+	if ok {
+		c.notify([]evicted[K, V]{{key: key, value: e.value, reason: EvictionDeleted}})
+	}
+	return ok
+}
+
+// Clear removes all entries
+func (c *Cache[K, V]) Clear() {
+	c.lock.Lock()
+	var ev []evicted[K, V]
+	if c.opts.OnEvict != nil {
+		for k, e := range c.entries.All() {
+			ev = append(ev, evicted[K, V]{key: k, value: e.value, reason: EvictionDeleted})
+		}
+	}
+	c.entries.Clear()
+	for _, call := range c.calls {
+		call.stale = true
+	}
+	c.lock.Unlock()
+
+	c.notify(ev)
+}
+
+// Len returns the number of entries, including expired entries not yet removed
+func (c *Cache[K, V]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.entries.Len()
+}
+
+// Keys returns the keys from the least to the most recently used,
+// including expired entries not yet removed
+func (c *Cache[K, V]) Keys() []K {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.entries.Keys()
+}
+
+// RemoveExpired removes expired entries, and returns the number of removed entries.
+// Expired entries are also removed when accessed.
+func (c *Cache[K, V]) RemoveExpired() int {
+	c.lock.Lock()
+	now := c.opts.Now()
+	var ev []evicted[K, V]
+	for k, e := range c.entries.All() {
+		if e.expired(now) {
+			c.entries.Delete(k)
+			c.stats.Evictions++
+			ev = append(ev, evicted[K, V]{key: k, value: e.value, reason: EvictionExpired})
+		}
+	}
+	c.lock.Unlock()
+
+	c.notify(ev)
+	return len(ev)
+}
+
+// Stats returns the cache statistics
+func (c *Cache[K, V]) Stats() CacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.stats
+}
+
+// GetOrLoad returns the value for the key, or loads it with the Loader on miss.
+// Concurrent calls for the same missing key wait for a single load,
+// and share its result. Errors are returned to all waiting callers and not cached.
+// The loaded value is not cached if the key is set, deleted or cleared during the load,
+// so that a stale value does not replace a newer one.
+// The loader is called with the context of the first caller,
+// other callers stop waiting when their context is done.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K) (V, error) {
+	return c.GetOrLoadWith(ctx, key, c.opts.Loader)
+}
+
+// GetOrLoadWith returns the value for the key, or loads it with the loader on miss,
+// see GetOrLoad
+func (c *Cache[K, V]) GetOrLoadWith(ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error)) (V, error) {
+	var zero V
+	if loader == nil {
+		return zero, errors.New("cache loader is not configured")
+	}
+
+	c.lock.Lock()
+	v, ok, ev := c.get(key)
+	if ok {
+		c.stats.Hits++
+		c.lock.Unlock()
+		c.notify(ev)
+		return v, nil
+	}
+	c.stats.Misses++
+
+	if call, ok := c.calls[key]; ok {
+		c.lock.Unlock()
+		c.notify(ev)
+		select {
+		case <-call.done:
+			return call.value, call.err
+		case <-ctx.Done():
+			return zero, errors.WithStack(ctx.Err())
+		}
+	}
+
+	call := &cacheCall[V]{done: make(chan struct{})}
+	c.calls[key] = call
+	c.lock.Unlock()
+	c.notify(ev)
+
+	c.load(ctx, key, call, loader)
+	return call.value, call.err
+}
+
+func (c *Cache[K, V]) load(ctx context.Context, key K, call *cacheCall[V], loader func(ctx context.Context, key K) (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero V
+			call.value = zero
+			call.err = errors.Errorf("cache loader panic: %v", r)
+		}
+
+		c.lock.Lock()
+		delete(c.calls, key)
+		c.stats.Loads++
+		var ev []evicted[K, V]
+		if call.err != nil {
+			c.stats.LoadErrors++
+		} else if !call.stale {
+			ev = c.set(key, call.value, c.opts.TTL)
+		}
+		c.lock.Unlock()
+
+		close(call.done)
+		c.notify(ev)
+	}()
+
+	call.value, call.err = loader(ctx, key)
+}
+
+// get returns the entry value, expired entry is removed.
+// Must be called under the lock.
+func (c *Cache[K, V]) get(key K) (V, bool, []evicted[K, V]) {
+	var zero V
+	e, ok := c.entries.Get(key)
+	if !ok {
+		return zero, false, nil
+	}
+	if e.expired(c.opts.Now()) {
+		c.entries.Delete(key)
+		c.stats.Evictions++
+		return zero, false, []evicted[K, V]{{key: key, value: e.value, reason: EvictionExpired}}
+	}
+	c.entries.MoveToBack(key)
+	return e.value, true, nil
+}
+
+// invalidate marks the pending load of the key as stale.
+// Must be called under the lock.
+func (c *Cache[K, V]) invalidate(key K) {
+	if call, ok := c.calls[key]; ok {
+		call.stale = true
+	}
+}
+
+// set adds the entry, and evicts the least recently used entries over MaxSize.
+// Must be called under the lock.
+func (c *Cache[K, V]) set(key K, value V, ttl time.Duration) []evicted[K, V] {
+	e := &cacheEntry[V]{value: value}
+	if ttl > 0 {
+		e.expires = c.opts.Now().Add(ttl)
+	}
+	c.entries.Set(key, e)
+	c.entries.MoveToBack(key)
+
+	var ev []evicted[K, V]
+	for c.opts.MaxSize > 0 && c.entries.Len() > c.opts.MaxSize {
+		k, old, _ := c.entries.Front()
+		c.entries.Delete(k)
+		c.stats.Evictions++
+		reason := EvictionCapacity
+		if old.expired(c.opts.Now()) {
+			reason = EvictionExpired
+		}
+		ev = append(ev, evicted[K, V]{key: k, value: old.value, reason: reason})
+	}
+	return ev
+}
+
+// notify calls OnEvict outside of the lock
+func (c *Cache[K, V]) notify(ev []evicted[K, V]) {
+	if c.opts.OnEvict == nil {
+		return
+	}
+	for _, e := range ev {
+		c.opts.OnEvict(e.key, e.value, e.reason)
+	}
+}
+
+func (e *cacheEntry[V]) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
@@ -0,0 +1,295 @@
+package maps_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/effective-security/x/maps"
+)
+
+type fakeClock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type evictedEntry struct {
+	key    string
+	value  int
+	reason maps.EvictionReason
+}
+
+func TestCache_LRU(t *testing.T) {
+	var ev []evictedEntry
+	c := maps.NewCache(maps.CacheOptions[string, int]{
+		MaxSize: 3,
+		OnEvict: func(key string, value int, reason maps.EvictionReason) {
+			ev = append(ev, evictedEntry{key, value, reason})
+		},
+	})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, []string{"b", "c", "a"}, c.Keys())
+
+	c.Set("d", 4)
+	assert.Equal(t, []string{"c", "a", "d"}, c.Keys())
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+
+	// replace does not call OnEvict, and marks as recently used
+	c.Set("c", 30)
+	assert.Equal(t, []string{"a", "d", "c"}, c.Keys())
+	v, _ = c.Get("c")
+	assert.Equal(t, 30, v)
+
+	assert.True(t, c.Delete("a"))
+	assert.False(t, c.Delete("a"))
+	assert.Equal(t, 2, c.Len())
+
+	c.Clear()
+	assert.Equal(t, 0, c.Len())
+
+	assert.Equal(t, []evictedEntry{
+		{"b", 2, maps.EvictionCapacity},
+		{"a", 1, maps.EvictionDeleted},
+		{"d", 4, maps.EvictionDeleted},
+		{"c", 30, maps.EvictionDeleted},
+	}, ev)
+
+	st := c.Stats()
+	assert.Equal(t, maps.CacheStats{Hits: 2, Misses: 1, Evictions: 1}, st)
+	assert.InDelta(t, 2.0/3, st.HitRatio(), 1e-9)
+	assert.Equal(t, 0.0, maps.CacheStats{}.HitRatio())
+}
+
+func TestCache_TTL(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var ev []evictedEntry
+	c := maps.NewCache(maps.CacheOptions[string, int]{
+		MaxSize: 3,
+		TTL:     time.Minute,
+		Now:     clock.Now,
+		OnEvict: func(key string, value int, reason maps.EvictionReason) {
+			ev = append(ev, evictedEntry{key, value, reason})
+		},
+	})
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, time.Hour)
+	c.SetWithTTL("forever", 3, 0)
+
+	clock.Add(59 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	clock.Add(time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	clock.Add(time.Hour)
+	assert.Equal(t, 1, c.RemoveExpired())
+	assert.Equal(t, 0, c.RemoveExpired())
+	v, ok := c.Get("forever")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	// expired entry evicted by capacity is reported as expired
+	c.Set("x", 10)
+	c.Set("y", 11)
+	c.Get("forever")
+	clock.Add(time.Minute)
+	c.Set("z", 12)
+
+	assert.Equal(t, []evictedEntry{
+		{"a", 1, maps.EvictionExpired},
+		{"b", 2, maps.EvictionExpired},
+		{"x", 10, maps.EvictionExpired},
+	}, ev)
+	assert.Equal(t, uint64(3), c.Stats().Evictions)
+	assert.Equal(t, "expired", maps.EvictionExpired.String())
+	assert.Equal(t, "capacity", maps.EvictionCapacity.String())
+	assert.Equal(t, "deleted", maps.EvictionDeleted.String())
+	assert.Equal(t, "unknown", maps.EvictionReason(0).String())
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	c := maps.NewCache(maps.CacheOptions[string, string]{
+		Loader: func(_ context.Context, key string) (string, error) {
+			calls.Add(1)
+			<-release
+			if key == "bad" {
+				return "", errors.New("not found")
+			}
+			return "value-" + key, nil
+		},
+	})
+
+	ctx := context.Background()
+	const n = 10
+	var wg sync.WaitGroup
+	results := make([]string, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := c.GetOrLoad(ctx, "k")
+			assert.NoError(t, err)
+			results[i] = v
+		}(i)
+	}
+	// wait for all callers to miss
+	require.Eventually(t, func() bool { return c.Stats().Misses == n }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, v := range results {
+		assert.Equal(t, "value-k", v)
+	}
+
+	v, err := c.GetOrLoad(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "value-k", v)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// errors are not cached
+	_, err = c.GetOrLoad(ctx, "bad")
+	assert.EqualError(t, err, "not found")
+	_, err = c.GetOrLoad(ctx, "bad")
+	assert.EqualError(t, err, "not found")
+	assert.Equal(t, int32(3), calls.Load())
+
+	st := c.Stats()
+	assert.Equal(t, uint64(1), st.Hits)
+	assert.Equal(t, uint64(n+2), st.Misses)
+	assert.Equal(t, uint64(3), st.Loads)
+	assert.Equal(t, uint64(2), st.LoadErrors)
+
+	// panics are returned as errors
+	v, err = c.GetOrLoadWith(ctx, "p", func(context.Context, string) (string, error) {
+		panic("boom")
+	})
+	assert.EqualError(t, err, "cache loader panic: boom")
+	assert.Empty(t, v)
+	_, ok := c.Get("p")
+	assert.False(t, ok)
+
+	_, err = maps.NewCache(maps.CacheOptions[string, int]{}).GetOrLoad(ctx, "k")
+	assert.EqualError(t, err, "cache loader is not configured")
+}
+
+func TestCache_GetOrLoadCanceled(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	c := maps.NewCache(maps.CacheOptions[int, int]{
+		Loader: func(_ context.Context, key int) (int, error) {
+			close(started)
+			<-release
+			return key * 2, nil
+		},
+	})
+
+	done := make(chan int)
+	go func() {
+		v, _ := c.GetOrLoad(context.Background(), 2)
+		done <- v
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.GetOrLoad(ctx, 2)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	assert.Equal(t, 4, <-done)
+	v, ok := c.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, 4, v)
+}
+
+func TestCache_GetOrLoadStale(t *testing.T) {
+	var started chan struct{}
+	var release chan struct{}
+	c := maps.NewCache(maps.CacheOptions[string, string]{
+		Loader: func(_ context.Context, key string) (string, error) {
+			close(started)
+			<-release
+			return "loaded", nil
+		},
+	})
+
+	load := func(change func()) (string, error) {
+		started = make(chan struct{})
+		release = make(chan struct{})
+		type result struct {
+			v   string
+			err error
+		}
+		done := make(chan result)
+		go func() {
+			v, err := c.GetOrLoad(context.Background(), "k")
+			done <- result{v, err}
+		}()
+		<-started
+		change()
+		close(release)
+		res := <-done
+		return res.v, res.err
+	}
+
+	// Set during the load is not overwritten
+	v, err := load(func() { c.Set("k", "newer") })
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+	v, ok := c.Get("k")
+	assert.True(t, ok)
+	assert.Equal(t, "newer", v)
+
+	// Delete during the load is not undone
+	c.Delete("k")
+	v, err = load(func() { c.Delete("k") })
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+	_, ok = c.Get("k")
+	assert.False(t, ok)
+
+	// Clear during the load is not undone
+	_, err = load(c.Clear)
+	require.NoError(t, err)
+	assert.Equal(t, 0, c.Len())
+
+	// otherwise the loaded value is cached
+	_, err = load(func() { c.Set("other", "x") })
+	require.NoError(t, err)
+	v, ok = c.Get("k")
+	assert.True(t, ok)
+	assert.Equal(t, "loaded", v)
+	assert.Equal(t, uint64(4), c.Stats().Loads)
+}
@@ -0,0 +1,165 @@
+package maps
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Change is the old and new value of a changed entry
+type Change[V any] struct {
+	Old V
+	New V
+}
+
+// MapDiff is the difference between two maps
+type MapDiff[K comparable, V any] struct {
+	// Added are the entries present only in the new map
+	Added map[K]V
+	// Removed are the entries present only in the old map
+	Removed map[K]V
+	// Changed are the entries present in both maps with different values
+	Changed map[K]Change[V]
+}
+
+// IsEmpty returns true if the maps are equal
+func (d MapDiff[K, V]) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff returns the difference from the old map a to the new map b.
+// The values are compared with eq, or reflect.DeepEqual if eq is nil.
+func Diff[K comparable, V any](a, b map[K]V, eq func(x, y V) bool) MapDiff[K, V] {
+	if eq == nil {
+		eq = func(x, y V) bool { return reflect.DeepEqual(x, y) }
+	}
+	d := MapDiff[K, V]{
+		Added:   make(map[K]V),
+		Removed: make(map[K]V),
+		Changed: make(map[K]Change[V]),
+	}
+	for k, old := range a {
+		v, ok := b[k]
+		if !ok {
+			d.Removed[k] = old
+		} else if !eq(old, v) {
+			d.Changed[k] = Change[V]{Old: old, New: v}
+		}
+	}
+	for k, v := range b {
+		if _, ok := a[k]; !ok {
+			d.Added[k] = v
+		}
+	}
+	return d
+}
+
+// ChangeOp specifies the kind of PathChange
+type ChangeOp int
+
+const (
+	// ChangeAdded is reported for a path present only in the new tree
+	ChangeAdded ChangeOp = iota + 1
+	// ChangeRemoved is reported for a path present only in the old tree
+	ChangeRemoved
+	// ChangeModified is reported for a path present in both trees with different values
+	ChangeModified
+)
+
+// String returns the operation name
+func (op ChangeOp) String() string {
+	switch op {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// PathChange is a change of a value in a tree of nested maps
+type PathChange struct {
+	// Path is the dot-separated list of keys from the root
+	Path string
+	Op   ChangeOp
+	// Old is nil for ChangeAdded
+	Old any
+	// New is nil for ChangeRemoved
+	New any
+}
+
+// String returns the change in the form suitable for logs:
+// `+ path: new`, `- path: old` or `~ path: old -> new`
+func (c PathChange) String() string {
+	switch c.Op {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %v", c.Path, c.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %v", c.Path, c.Old)
+	}
+	return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// DiffTree returns the changes from the old tree a to the new tree b, sorted by path.
+// Nested maps with string keys, such as map[string]any or values.MapAny,
+// are compared recursively, and other values with reflect.DeepEqual.
+// A nested map replaced by a value of another type is reported as modified.
+func DiffTree(a, b map[string]any) []PathChange {
+	var changes []PathChange
+	diffTree("", reflect.ValueOf(a), reflect.ValueOf(b), &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func diffTree(prefix string, a, b reflect.Value, changes *[]PathChange) {
+	for _, k := range a.MapKeys() {
+		path := joinPath(prefix, k.String())
+		old := a.MapIndex(k)
+		v := mapIndex(b, k.String())
+		if !v.IsValid() {
+			*changes = append(*changes, PathChange{Path: path, Op: ChangeRemoved, Old: old.Interface()})
+			continue
+		}
+		om, vm := treeNode(old), treeNode(v)
+		if om.IsValid() && vm.IsValid() {
+			diffTree(path, om, vm, changes)
+		} else if !reflect.DeepEqual(old.Interface(), v.Interface()) {
+			*changes = append(*changes, PathChange{Path: path, Op: ChangeModified, Old: old.Interface(), New: v.Interface()})
+		}
+	}
+	for _, k := range b.MapKeys() {
+		if !mapIndex(a, k.String()).IsValid() {
+			*changes = append(*changes, PathChange{Path: joinPath(prefix, k.String()), Op: ChangeAdded, New: b.MapIndex(k).Interface()})
+		}
+	}
+}
+
+// mapIndex returns the value for the key, the maps in a tree may have different key types
+func mapIndex(m reflect.Value, key string) reflect.Value {
+	return m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key()))
+}
+
+// treeNode returns the map with string keys held by v, or invalid value
+func treeNode(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+		return v
+	}
+	return reflect.Value{}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
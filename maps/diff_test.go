@@ -0,0 +1,96 @@
+package maps_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/effective-security/x/maps"
+)
+
+func TestDiff(t *testing.T) {
+	a := map[string]int{"a": 1, "b": 2, "c": 3}
+	b := map[string]int{"b": 2, "c": 4, "d": 5}
+
+	d := maps.Diff(a, b, nil)
+	assert.False(t, d.IsEmpty())
+	assert.Equal(t, map[string]int{"d": 5}, d.Added)
+	assert.Equal(t, map[string]int{"a": 1}, d.Removed)
+	assert.Equal(t, map[string]maps.Change[int]{"c": {Old: 3, New: 4}}, d.Changed)
+
+	assert.True(t, maps.Diff(a, a, nil).IsEmpty())
+	assert.True(t, maps.Diff[string, int](nil, nil, nil).IsEmpty())
+	assert.Equal(t, a, maps.Diff(nil, a, nil).Added)
+
+	// case-insensitive values
+	flags := map[string]string{"x": "On", "y": "off"}
+	flags2 := map[string]string{"x": "on", "y": "on"}
+	d2 := maps.Diff(flags, flags2, strings.EqualFold)
+	assert.Equal(t, map[string]maps.Change[string]{"y": {Old: "off", New: "on"}}, d2.Changed)
+
+	// slices are compared with reflect.DeepEqual by default
+	d3 := maps.Diff(map[int][]string{1: {"a"}}, map[int][]string{1: {"a"}}, nil)
+	assert.True(t, d3.IsEmpty())
+}
+
+type settings map[string]any
+
+func TestDiffTree(t *testing.T) {
+	a := map[string]any{
+		"name": "tenant",
+		"limits": map[string]any{
+			"users":   10,
+			"storage": "1G",
+			"api":     map[string]any{"rps": 100},
+		},
+		"flags":   []string{"a", "b"},
+		"removed": true,
+		"shape":   map[string]any{"x": 1},
+		"typed":   settings{"a": 1, "b": map[string]string{"c": "d"}},
+	}
+	b := map[string]any{
+		"name": "tenant",
+		"limits": map[string]any{
+			"users":   20,
+			"storage": "1G",
+			"api":     map[string]any{"rps": 100, "burst": 10},
+		},
+		"flags": []string{"a", "c"},
+		"added": "yes",
+		"shape": 1,
+		"typed": map[string]any{"a": 1, "b": map[string]string{"c": "e"}},
+	}
+
+	changes := maps.DiffTree(a, b)
+	assert.Equal(t, []maps.PathChange{
+		{Path: "added", Op: maps.ChangeAdded, New: "yes"},
+		{Path: "flags", Op: maps.ChangeModified, Old: []string{"a", "b"}, New: []string{"a", "c"}},
+		{Path: "limits.api.burst", Op: maps.ChangeAdded, New: 10},
+		{Path: "limits.users", Op: maps.ChangeModified, Old: 10, New: 20},
+		{Path: "removed", Op: maps.ChangeRemoved, Old: true},
+		{Path: "shape", Op: maps.ChangeModified, Old: map[string]any{"x": 1}, New: 1},
+		{Path: "typed.b.c", Op: maps.ChangeModified, Old: "d", New: "e"},
+	}, changes)
+
+	var lines []string
+	for _, c := range changes[:5] {
+		lines = append(lines, c.String())
+	}
+	assert.Equal(t, []string{
+		"+ added: yes",
+		"~ flags: [a b] -> [a c]",
+		"+ limits.api.burst: 10",
+		"~ limits.users: 10 -> 20",
+		"- removed: true",
+	}, lines)
+
+	assert.Empty(t, maps.DiffTree(a, a))
+	assert.Empty(t, maps.DiffTree(nil, nil))
+	assert.Len(t, maps.DiffTree(nil, a), len(a))
+
+	assert.Equal(t, "added", maps.ChangeAdded.String())
+	assert.Equal(t, "removed", maps.ChangeRemoved.String())
+	assert.Equal(t, "modified", maps.ChangeModified.String())
+	assert.Equal(t, "unknown", maps.ChangeOp(0).String())
+}
@@ -211,7 +211,8 @@ func Max[K comparable, V any](m map[K]V, less func(a, b V) bool) (K, V, bool) {
 	return maxKey, maxValue, true
 }
 
-// Take returns the first n key-value pairs from the map
+// Take returns the first n key-value pairs from the map in random order,
+// use TakeOrdered for a deterministic result
 func Take[K comparable, V any](m map[K]V, n int) map[K]V {
 	if n <= 0 {
 		return make(map[K]V)
@@ -1,7 +1,15 @@
 package maps
 
-import "sync"
+import (
+	"encoding/json"
+	"iter"
+	"sync"
 
+	"github.com/cockroachdb/errors"
+)
+
+// SyncMap is a type-safe wrapper of sync.Map.
+// The zero value is an empty map ready to use.
 type SyncMap[K comparable, V any] struct {
 	m sync.Map
 }
@@ -33,6 +41,18 @@ func (m *SyncMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
 	return a.(V), loaded
 }
 
+// LoadOrCompute returns the existing value for the key if present.
+// Otherwise, it calls compute, stores and returns its result.
+// The loaded result is true if the value was loaded, false if computed.
+// Concurrent callers for the same missing key may call compute more than once,
+// but only one of the results is stored and returned to all of them.
+func (m *SyncMap[K, V]) LoadOrCompute(key K, compute func() V) (actual V, loaded bool) {
+	if v, ok := m.m.Load(key); ok {
+		return v.(V), true
+	}
+	return m.LoadOrStore(key, compute())
+}
+
 func (m *SyncMap[K, V]) Range(f func(key K, value V) bool) {
 	m.m.Range(func(key, value any) bool { return f(key.(K), value.(V)) })
 }
@@ -40,3 +60,84 @@ func (m *SyncMap[K, V]) Range(f func(key K, value V) bool) {
 func (m *SyncMap[K, V]) Store(key K, value V) {
 	m.m.Store(key, value)
 }
+
+// Swap stores the value for the key and returns the previous value if any.
+// The loaded result reports whether the key was present.
+func (m *SyncMap[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	p, loaded := m.m.Swap(key, value)
+	if !loaded {
+		var zero V
+		return zero, loaded
+	}
+	return p.(V), loaded
+}
+
+// CompareAndSwap swaps the old and new values for the key
+// if the value stored in the map is equal to old.
+// As sync.Map, it panics if V is not comparable.
+func (m *SyncMap[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	return m.m.CompareAndSwap(key, old, new)
+}
+
+// CompareAndDelete deletes the entry for the key if its value is equal to old.
+// As sync.Map, it panics if V is not comparable.
+func (m *SyncMap[K, V]) CompareAndDelete(key K, old V) (deleted bool) {
+	return m.m.CompareAndDelete(key, old)
+}
+
+// Clear deletes all the entries
+func (m *SyncMap[K, V]) Clear() {
+	m.m.Clear()
+}
+
+// Len returns the number of entries.
+// It iterates over the map, and the result is approximate
+// if the map is modified concurrently.
+func (m *SyncMap[K, V]) Len() int {
+	n := 0
+	m.m.Range(func(_, _ any) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// All returns an iterator over key-value pairs, with the same
+// consistency guarantees as Range
+func (m *SyncMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.Range(yield)
+	}
+}
+
+// Snapshot returns a copy of the entries as a map
+func (m *SyncMap[K, V]) Snapshot() map[K]V {
+	res := make(map[K]V)
+	m.Range(func(key K, value V) bool {
+		res[key] = value
+		return true
+	})
+	return res
+}
+
+// MarshalJSON implements json.Marshaler, and encodes a snapshot of the entries
+func (m *SyncMap[K, V]) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(m.Snapshot())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, and stores the decoded entries.
+// Existing entries are kept.
+func (m *SyncMap[K, V]) UnmarshalJSON(data []byte) error {
+	var entries map[K]V
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return errors.WithStack(err)
+	}
+	for k, v := range entries {
+		m.Store(k, v)
+	}
+	return nil
+}
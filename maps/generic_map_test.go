@@ -1,7 +1,9 @@
 package maps_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -436,3 +438,124 @@ func TestSyncMapZeroValueHandling(t *testing.T) {
 	assert.False(t, ok)
 	assert.Equal(t, "", valStr) // zero value for string
 }
+
+func TestSyncMapSwapAndCompare(t *testing.T) {
+	var m maps.SyncMap[string, int]
+
+	prev, loaded := m.Swap("a", 1)
+	assert.False(t, loaded)
+	assert.Equal(t, 0, prev)
+	prev, loaded = m.Swap("a", 2)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, prev)
+
+	assert.False(t, m.CompareAndSwap("a", 1, 3))
+	assert.True(t, m.CompareAndSwap("a", 2, 3))
+	assert.False(t, m.CompareAndSwap("missing", 0, 1))
+	v, _ := m.Load("a")
+	assert.Equal(t, 3, v)
+
+	assert.False(t, m.CompareAndDelete("a", 2))
+	assert.True(t, m.CompareAndDelete("a", 3))
+	_, ok := m.Load("a")
+	assert.False(t, ok)
+
+	var s maps.SyncMap[string, []int]
+	s.Store("a", []int{1})
+	assert.Panics(t, func() {
+		s.CompareAndSwap("a", []int{1}, []int{2})
+	})
+}
+
+func TestSyncMapLenClearSnapshot(t *testing.T) {
+	var m maps.SyncMap[int, string]
+	assert.Equal(t, 0, m.Len())
+	assert.Empty(t, m.Snapshot())
+
+	for i := 0; i < 5; i++ {
+		m.Store(i, fmt.Sprint(i))
+	}
+	assert.Equal(t, 5, m.Len())
+
+	snap := m.Snapshot()
+	assert.Equal(t, map[int]string{0: "0", 1: "1", 2: "2", 3: "3", 4: "4"}, snap)
+	// snapshot is a copy
+	m.Delete(0)
+	assert.Len(t, snap, 5)
+
+	got := map[int]string{}
+	for k, v := range m.All() {
+		got[k] = v
+	}
+	assert.Equal(t, map[int]string{1: "1", 2: "2", 3: "3", 4: "4"}, got)
+
+	count := 0
+	for range m.All() {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+
+	m.Clear()
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestSyncMapLoadOrCompute(t *testing.T) {
+	var m maps.SyncMap[string, *int]
+	calls := 0
+	compute := func() *int {
+		calls++
+		v := calls
+		return &v
+	}
+
+	v, loaded := m.LoadOrCompute("a", compute)
+	assert.False(t, loaded)
+	assert.Equal(t, 1, *v)
+
+	v2, loaded := m.LoadOrCompute("a", compute)
+	assert.True(t, loaded)
+	assert.Same(t, v, v2)
+	assert.Equal(t, 1, calls)
+
+	// concurrent callers get the same stored value
+	var cm maps.SyncMap[int, *int]
+	var wg sync.WaitGroup
+	results := make([]*int, 50)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = cm.LoadOrCompute(1, func() *int { return new(int) })
+		}(i)
+	}
+	wg.Wait()
+	for _, r := range results {
+		assert.Same(t, results[0], r)
+	}
+}
+
+func TestSyncMapJSON(t *testing.T) {
+	type config struct {
+		Hosts *maps.SyncMap[string, int] `json:"hosts"`
+	}
+	c := config{Hosts: &maps.SyncMap[string, int]{}}
+	c.Hosts.Store("b", 2)
+	c.Hosts.Store("a", 1)
+
+	b, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Equal(t, `{"hosts":{"a":1,"b":2}}`, string(b))
+
+	var c2 config
+	require.NoError(t, json.Unmarshal(b, &c2))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, c2.Hosts.Snapshot())
+
+	var m maps.SyncMap[string, int]
+	assert.Error(t, json.Unmarshal([]byte(`{"a":"x"}`), &m))
+
+	var bad maps.SyncMap[string, func()]
+	bad.Store("f", func() {})
+	_, err = json.Marshal(&bad)
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+package maps
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// Seq returns an iterator over key-value pairs of the map in random order
+func Seq[K comparable, V any](m map[K]V) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range m {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq returns an iterator over key-value pairs that satisfy the predicate
+func FilterSeq[K, V any](seq iter.Seq2[K, V], predicate func(key K, value V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if predicate(k, v) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// MapValuesSeq returns an iterator over keys with values transformed by f
+func MapValuesSeq[K, V, R any](seq iter.Seq2[K, V], f func(key K, value V) R) iter.Seq2[K, R] {
+	return func(yield func(K, R) bool) {
+		for k, v := range seq {
+			if !yield(k, f(k, v)) {
+				return
+			}
+		}
+	}
+}
+
+// SortedByKey returns an iterator over key-value pairs in ascending order of the keys.
+// The pairs are collected when the iteration starts.
+func SortedByKey[K cmp.Ordered, V any](seq iter.Seq2[K, V]) iter.Seq2[K, V] {
+	return SortedFunc(seq, func(k1 K, _ V, k2 K, _ V) int {
+		return cmp.Compare(k1, k2)
+	})
+}
+
+// SortedByValue returns an iterator over key-value pairs in ascending order of the values,
+// pairs with equal values are ordered by the keys.
+// The pairs are collected when the iteration starts.
+func SortedByValue[K, V cmp.Ordered](seq iter.Seq2[K, V]) iter.Seq2[K, V] {
+	return SortedFunc(seq, func(k1 K, v1 V, k2 K, v2 V) int {
+		if c := cmp.Compare(v1, v2); c != 0 {
+			return c
+		}
+		return cmp.Compare(k1, k2)
+	})
+}
+
+// SortedFunc returns an iterator over key-value pairs sorted by the comparison function,
+// which returns a negative number if the first pair is less than the second,
+// a positive number if greater, and zero if equal.
+// Equal pairs keep the order of seq.
+// The pairs are collected when the iteration starts.
+func SortedFunc[K, V any](seq iter.Seq2[K, V], compare func(k1 K, v1 V, k2 K, v2 V) int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		entries := collectEntries(seq)
+		slices.SortStableFunc(entries, func(a, b entry[K, V]) int {
+			return compare(a.key, a.value, b.key, b.value)
+		})
+		for _, e := range entries {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// TakeOrdered returns an iterator over the n key-value pairs with the smallest keys,
+// in ascending order of the keys.
+// Unlike Take, the result is deterministic.
+func TakeOrdered[K cmp.Ordered, V any](seq iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	return Limit(SortedByKey(seq), n)
+}
+
+// Limit returns an iterator over the first n key-value pairs of seq
+func Limit[K, V any](seq iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for k, v := range seq {
+			if !yield(k, v) {
+				return
+			}
+			i++
+			if i >= n {
+				return
+			}
+		}
+	}
+}
+
+// Skip returns an iterator over key-value pairs of seq after the first n
+func Skip[K, V any](seq iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		i := 0
+		for k, v := range seq {
+			if i < n {
+				i++
+				continue
+			}
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Chain returns an iterator over key-value pairs of all sequences, one after another
+func Chain[K, V any](seqs ...iter.Seq2[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, seq := range seqs {
+			for k, v := range seq {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Collect returns a map with the key-value pairs of seq,
+// later pairs take precedence for duplicate keys
+func Collect[K comparable, V any](seq iter.Seq2[K, V]) map[K]V {
+	res := make(map[K]V)
+	for k, v := range seq {
+		res[k] = v
+	}
+	return res
+}
+
+// CollectOrdered returns an OrderedMap with the key-value pairs of seq in the order of seq,
+// later pairs take precedence for duplicate keys, and keep the position of the first one
+func CollectOrdered[K comparable, V any](seq iter.Seq2[K, V]) *OrderedMap[K, V] {
+	res := NewOrderedMap[K, V]()
+	for k, v := range seq {
+		res.Set(k, v)
+	}
+	return res
+}
+
+// CollectKeys returns the keys of seq as a slice, in the order of seq
+func CollectKeys[K, V any](seq iter.Seq2[K, V]) []K {
+	var res []K
+	for k := range seq {
+		res = append(res, k)
+	}
+	return res
+}
+
+// CollectValues returns the values of seq as a slice, in the order of seq
+func CollectValues[K, V any](seq iter.Seq2[K, V]) []V {
+	var res []V
+	for _, v := range seq {
+		res = append(res, v)
+	}
+	return res
+}
+
+type entry[K, V any] struct {
+	key   K
+	value V
+}
+
+func collectEntries[K, V any](seq iter.Seq2[K, V]) []entry[K, V] {
+	var entries []entry[K, V]
+	for k, v := range seq {
+		entries = append(entries, entry[K, V]{key: k, value: v})
+	}
+	return entries
+}
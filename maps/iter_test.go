@@ -0,0 +1,79 @@
+package maps_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/effective-security/x/maps"
+)
+
+func TestSeqPipeline(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+
+	seq := maps.FilterSeq(maps.Seq(m), func(_ string, v int) bool { return v%2 == 1 })
+	upper := maps.MapValuesSeq(seq, func(k string, v int) string { return strings.ToUpper(k) })
+	assert.Equal(t, map[string]string{"a": "A", "c": "C", "e": "E"}, maps.Collect(upper))
+
+	sorted := maps.SortedByKey(maps.FilterSeq(maps.Seq(m), func(_ string, v int) bool { return v > 2 }))
+	assert.Equal(t, []string{"c", "d", "e"}, maps.CollectKeys(sorted))
+	assert.Equal(t, []int{3, 4, 5}, maps.CollectValues(sorted))
+
+	om := maps.CollectOrdered(maps.SortedByKey(maps.Seq(m)))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, om.Keys())
+
+	assert.Empty(t, maps.Collect(maps.Seq(map[string]int(nil))))
+	assert.Nil(t, maps.CollectKeys(maps.Seq(map[string]int(nil))))
+}
+
+func TestSortedByValue(t *testing.T) {
+	m := map[string]int{"x": 2, "b": 1, "a": 2, "c": 0}
+	sorted := maps.SortedByValue(maps.Seq(m))
+	assert.Equal(t, []string{"c", "b", "a", "x"}, maps.CollectKeys(sorted))
+
+	desc := maps.SortedFunc(maps.Seq(m), func(k1 string, v1 int, k2 string, v2 int) int {
+		if v1 != v2 {
+			return v2 - v1
+		}
+		return strings.Compare(k1, k2)
+	})
+	assert.Equal(t, []string{"a", "x", "b", "c"}, maps.CollectKeys(desc))
+
+	// early stop
+	for k := range sorted {
+		assert.Equal(t, "c", k)
+		break
+	}
+}
+
+func TestTakeOrdered(t *testing.T) {
+	m := map[int]string{5: "e", 1: "a", 3: "c", 2: "b", 4: "d"}
+
+	for range 10 {
+		assert.Equal(t, []int{1, 2, 3}, maps.CollectKeys(maps.TakeOrdered(maps.Seq(m), 3)))
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, maps.CollectKeys(maps.TakeOrdered(maps.Seq(m), 10)))
+	assert.Empty(t, maps.CollectKeys(maps.TakeOrdered(maps.Seq(m), 0)))
+
+	assert.Equal(t, []int{4, 5}, maps.CollectKeys(maps.Skip(maps.SortedByKey(maps.Seq(m)), 3)))
+	assert.Empty(t, maps.CollectKeys(maps.Skip(maps.Seq(m), 5)))
+	assert.Len(t, maps.CollectKeys(maps.Skip(maps.Seq(m), -1)), 5)
+	assert.Len(t, maps.CollectKeys(maps.Limit(maps.Seq(m), 2)), 2)
+}
+
+func TestChain(t *testing.T) {
+	a := maps.SortedByKey(maps.Seq(map[string]int{"b": 2, "a": 1}))
+	b := maps.SortedByKey(maps.Seq(map[string]int{"c": 3, "a": 10}))
+
+	chained := maps.Chain(a, b)
+	assert.Equal(t, []string{"a", "b", "a", "c"}, maps.CollectKeys(chained))
+	assert.Equal(t, map[string]int{"a": 10, "b": 2, "c": 3}, maps.Collect(chained))
+
+	om := maps.CollectOrdered(chained)
+	assert.Equal(t, []string{"a", "b", "c"}, om.Keys())
+	assert.Equal(t, []int{10, 2, 3}, om.Values())
+
+	assert.Equal(t, []string{"a", "b", "a"}, maps.CollectKeys(maps.Limit(chained, 3)))
+	assert.Empty(t, maps.Collect(maps.Chain[string, int]()))
+}
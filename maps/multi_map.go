@@ -0,0 +1,165 @@
+package maps
+
+import (
+	"encoding/json"
+	"iter"
+
+	"github.com/cockroachdb/errors"
+)
+
+// MultiMap is a map of keys to sets of values.
+// Values of a key are unique, and kept in order of addition.
+// The zero value is an empty map ready to use.
+// MultiMap is not safe for concurrent use.
+type MultiMap[K, V comparable] struct {
+	m    map[K]*OrderedMap[V, struct{}]
+	size int
+}
+
+// NewMultiMap returns an empty multimap
+func NewMultiMap[K, V comparable]() *MultiMap[K, V] {
+	return &MultiMap[K, V]{}
+}
+
+// Add adds the values to the key, and returns the number of added values,
+// values already present for the key are ignored
+func (m *MultiMap[K, V]) Add(key K, values ...V) int {
+	if len(values) == 0 {
+		return 0
+	}
+	set, ok := m.m[key]
+	if !ok {
+		if m.m == nil {
+			m.m = make(map[K]*OrderedMap[V, struct{}])
+		}
+		set = NewOrderedMap[V, struct{}]()
+		m.m[key] = set
+	}
+	added := 0
+	for _, v := range values {
+		if !set.Has(v) {
+			set.Set(v, struct{}{})
+			added++
+		}
+	}
+	m.size += added
+	return added
+}
+
+// Remove removes the value from the key, and returns true if the value was present.
+// The key is removed with its last value.
+func (m *MultiMap[K, V]) Remove(key K, value V) bool {
+	set, ok := m.m[key]
+	if !ok || !set.Delete(value) {
+		return false
+	}
+	m.size--
+	if set.Len() == 0 {
+		delete(m.m, key)
+	}
+	return true
+}
+
+// RemoveKey removes the key with all its values, and returns the removed values
+func (m *MultiMap[K, V]) RemoveKey(key K) []V {
+	set, ok := m.m[key]
+	if !ok {
+		return nil
+	}
+	delete(m.m, key)
+	m.size -= set.Len()
+	return set.Keys()
+}
+
+// Get returns the values of the key in order of addition
+func (m *MultiMap[K, V]) Get(key K) []V {
+	set, ok := m.m[key]
+	if !ok {
+		return nil
+	}
+	return set.Keys()
+}
+
+// Has returns true if the key has the value
+func (m *MultiMap[K, V]) Has(key K, value V) bool {
+	set, ok := m.m[key]
+	return ok && set.Has(value)
+}
+
+// HasKey returns true if the key has any values
+func (m *MultiMap[K, V]) HasKey(key K) bool {
+	_, ok := m.m[key]
+	return ok
+}
+
+// Keys returns the keys in random order
+func (m *MultiMap[K, V]) Keys() []K {
+	return Keys(m.m)
+}
+
+// Len returns the number of keys
+func (m *MultiMap[K, V]) Len() int {
+	return len(m.m)
+}
+
+// Size returns the number of key-value pairs
+func (m *MultiMap[K, V]) Size() int {
+	return m.size
+}
+
+// Clear removes all entries
+func (m *MultiMap[K, V]) Clear() {
+	m.m = nil
+	m.size = 0
+}
+
+// All returns an iterator over all key-value pairs, the keys are in random order,
+// and the values of each key in order of addition
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, set := range m.m {
+			for v := range set.All() {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Sets returns an iterator over the keys and their values
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		for k, set := range m.m {
+			if !yield(k, set.Keys()) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalJSON implements json.Marshaler, the map is encoded as an object of arrays
+func (m MultiMap[K, V]) MarshalJSON() ([]byte, error) {
+	res := make(map[K][]V, len(m.m))
+	for k, set := range m.m {
+		res[k] = set.Keys()
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, and adds the decoded values.
+// Existing entries are kept.
+func (m *MultiMap[K, V]) UnmarshalJSON(data []byte) error {
+	var entries map[K][]V
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return errors.WithStack(err)
+	}
+	for k, values := range entries {
+		m.Add(k, values...)
+	}
+	return nil
+}
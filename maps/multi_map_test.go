@@ -0,0 +1,96 @@
+package maps_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/effective-security/x/maps"
+)
+
+func TestMultiMap(t *testing.T) {
+	var m maps.MultiMap[string, int]
+	assert.Nil(t, m.Get("a"))
+	assert.False(t, m.Remove("a", 1))
+
+	assert.Equal(t, 3, m.Add("a", 3, 1, 2))
+	assert.Equal(t, 1, m.Add("a", 1, 4))
+	assert.Equal(t, 0, m.Add("b"))
+	assert.False(t, m.HasKey("b"))
+	assert.Equal(t, 1, m.Add("b", 1))
+
+	assert.Equal(t, []int{3, 1, 2, 4}, m.Get("a"))
+	assert.True(t, m.Has("a", 2))
+	assert.False(t, m.Has("b", 2))
+	assert.ElementsMatch(t, []string{"a", "b"}, m.Keys())
+	assert.Equal(t, 2, m.Len())
+	assert.Equal(t, 5, m.Size())
+
+	assert.True(t, m.Remove("a", 1))
+	assert.False(t, m.Remove("a", 1))
+	assert.Equal(t, []int{3, 2, 4}, m.Get("a"))
+
+	assert.True(t, m.Remove("b", 1))
+	assert.False(t, m.HasKey("b"))
+	assert.Equal(t, 1, m.Len())
+	assert.Equal(t, 3, m.Size())
+
+	count := 0
+	for k, v := range m.All() {
+		assert.Equal(t, "a", k)
+		assert.True(t, m.Has(k, v))
+		count++
+	}
+	assert.Equal(t, 3, count)
+
+	for k, values := range m.Sets() {
+		assert.Equal(t, "a", k)
+		assert.Equal(t, []int{3, 2, 4}, values)
+	}
+
+	assert.Equal(t, []int{3, 2, 4}, m.RemoveKey("a"))
+	assert.Nil(t, m.RemoveKey("a"))
+	assert.Equal(t, 0, m.Size())
+
+	m.Add("c", 1)
+	m.Clear()
+	assert.Equal(t, 0, m.Len())
+	assert.Equal(t, 0, m.Size())
+}
+
+func TestMultiMapJSON(t *testing.T) {
+	m := maps.NewMultiMap[string, string]()
+	m.Add("admins", "bob", "alice")
+	m.Add("users", "carol")
+
+	b, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.Equal(t, `{"admins":["bob","alice"],"users":["carol"]}`, string(b))
+
+	var m2 maps.MultiMap[string, string]
+	m2.Add("users", "carol", "dave")
+	require.NoError(t, json.Unmarshal(b, &m2))
+	assert.Equal(t, []string{"bob", "alice"}, m2.Get("admins"))
+	assert.Equal(t, []string{"carol", "dave"}, m2.Get("users"))
+	assert.Equal(t, 4, m2.Size())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"a":1}`), &m2))
+}
+
+func TestMultiMapJSON_ValueField(t *testing.T) {
+	type config struct {
+		Groups maps.MultiMap[string, string] `json:"groups"`
+	}
+	var c config
+	c.Groups.Add("admins", "bob")
+
+	b, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Equal(t, `{"groups":{"admins":["bob"]}}`, string(b))
+
+	var c2 config
+	require.NoError(t, json.Unmarshal(b, &c2))
+	assert.Equal(t, []string{"bob"}, c2.Groups.Get("admins"))
+}
@@ -0,0 +1,403 @@
+package maps
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"iter"
+	"reflect"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// OrderedMap is a map that preserves insertion order of the keys.
+// JSON and YAML encoding keeps the order of the keys,
+// nested objects are decoded as V, so use OrderedMap as V
+// to keep the order of nested keys.
+// The zero value is an empty map ready to use.
+// OrderedMap is not safe for concurrent use.
+type OrderedMap[K comparable, V any] struct {
+	m    map[K]*orderedEntry[K, V]
+	head *orderedEntry[K, V]
+	tail *orderedEntry[K, V]
+}
+
+type orderedEntry[K comparable, V any] struct {
+	key   K
+	value V
+	prev  *orderedEntry[K, V]
+	next  *orderedEntry[K, V]
+}
+
+// NewOrderedMap returns an empty ordered map
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{}
+}
+
+// Len returns the number of entries
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.m)
+}
+
+// Set sets the value for the key.
+// A new key is added to the back, an existing key keeps its position.
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if e, ok := m.m[key]; ok {
+		e.value = value
+		return
+	}
+	if m.m == nil {
+		m.m = make(map[K]*orderedEntry[K, V])
+	}
+	e := &orderedEntry[K, V]{key: key, value: value}
+	m.m[key] = e
+	m.pushBack(e)
+}
+
+// Get returns the value for the key
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := m.m[key]; ok {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Has returns true if the map contains the key
+func (m *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := m.m[key]
+	return ok
+}
+
+// Delete removes the key, and returns true if the key was present
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	e, ok := m.m[key]
+	if !ok {
+		return false
+	}
+	delete(m.m, key)
+	prev, next := e.prev, e.next
+	m.unlink(e)
+	// keep the links of the removed entry, so iterators positioned
+	// on it can continue to the entries that are still present
+	e.prev, e.next = prev, next
+	return true
+}
+
+// Clear removes all entries
+func (m *OrderedMap[K, V]) Clear() {
+	m.m = nil
+	m.head = nil
+	m.tail = nil
+}
+
+// MoveToFront moves the key to the front, and returns false if the key is not present
+func (m *OrderedMap[K, V]) MoveToFront(key K) bool {
+	e, ok := m.m[key]
+	if !ok {
+		return false
+	}
+	if m.head != e {
+		m.unlink(e)
+		m.pushFront(e)
+	}
+	return true
+}
+
+// MoveToBack moves the key to the back, and returns false if the key is not present
+func (m *OrderedMap[K, V]) MoveToBack(key K) bool {
+	e, ok := m.m[key]
+	if !ok {
+		return false
+	}
+	if m.tail != e {
+		m.unlink(e)
+		m.pushBack(e)
+	}
+	return true
+}
+
+// Front returns the first entry
+func (m *OrderedMap[K, V]) Front() (K, V, bool) {
+	if m.head == nil {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	return m.head.key, m.head.value, true
+}
+
+// Back returns the last entry
+func (m *OrderedMap[K, V]) Back() (K, V, bool) {
+	if m.tail == nil {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	return m.tail.key, m.tail.value, true
+}
+
+// Keys returns all keys in order
+func (m *OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, len(m.m))
+	for e := m.head; e != nil; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Values returns all values in order of the keys
+func (m *OrderedMap[K, V]) Values() []V {
+	values := make([]V, 0, len(m.m))
+	for e := m.head; e != nil; e = e.next {
+		values = append(values, e.value)
+	}
+	return values
+}
+
+// All returns an iterator over key-value pairs in order.
+// Keys can be deleted during the iteration, deleted keys are not yielded.
+// The current key can be moved, other keys moved during the iteration
+// are yielded at their new position.
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := m.skipRemoved(m.head, true); e != nil; {
+			// take next before yield, as the entry can be deleted or moved
+			next := e.next
+			if !yield(e.key, e.value) {
+				return
+			}
+			e = m.skipRemoved(next, true)
+		}
+	}
+}
+
+// Backward returns an iterator over key-value pairs in reverse order,
+// with the same guarantees as All
+func (m *OrderedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := m.skipRemoved(m.tail, false); e != nil; {
+			prev := e.prev
+			if !yield(e.key, e.value) {
+				return
+			}
+			e = m.skipRemoved(prev, false)
+		}
+	}
+}
+
+// skipRemoved returns the first entry starting from e that is still in the map,
+// following the links kept by Delete
+func (m *OrderedMap[K, V]) skipRemoved(e *orderedEntry[K, V], forward bool) *orderedEntry[K, V] {
+	for e != nil && m.m[e.key] != e {
+		if forward {
+			e = e.next
+		} else {
+			e = e.prev
+		}
+	}
+	return e
+}
+
+// Clone returns a shallow copy of the map
+func (m *OrderedMap[K, V]) Clone() *OrderedMap[K, V] {
+	c := NewOrderedMap[K, V]()
+	for e := m.head; e != nil; e = e.next {
+		c.Set(e.key, e.value)
+	}
+	return c
+}
+
+func (m *OrderedMap[K, V]) pushBack(e *orderedEntry[K, V]) {
+	e.prev = m.tail
+	e.next = nil
+	if m.tail != nil {
+		m.tail.next = e
+	} else {
+		m.head = e
+	}
+	m.tail = e
+}
+
+func (m *OrderedMap[K, V]) pushFront(e *orderedEntry[K, V]) {
+	e.prev = nil
+	e.next = m.head
+	if m.head != nil {
+		m.head.prev = e
+	} else {
+		m.tail = e
+	}
+	m.head = e
+}
+
+func (m *OrderedMap[K, V]) unlink(e *orderedEntry[K, V]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		m.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		m.tail = e.prev
+	}
+	e.prev = nil
+	e.next = nil
+}
+
+// MarshalJSON implements json.Marshaler, keys are encoded in order.
+// Keys must be strings, integers or implement encoding.TextMarshaler.
+func (m OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for e := m.head; e != nil; e = e.next {
+		if e != m.head {
+			buf.WriteByte(',')
+		}
+		key, err := marshalKey(e.key)
+		if err != nil {
+			return nil, err
+		}
+		kb, err := json.Marshal(key)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		vb, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, entries are added in order of the keys.
+// Existing entries are kept, null is ignored.
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return errors.Errorf("expected JSON object, got %v", tok)
+	}
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var key K
+		if err = unmarshalKey(tok.(string), &key); err != nil {
+			return err
+		}
+		var value V
+		if err = dec.Decode(&value); err != nil {
+			return errors.WithStack(err)
+		}
+		m.Set(key, value)
+	}
+	if _, err = dec.Token(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler, keys are encoded in order
+func (m OrderedMap[K, V]) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for e := m.head; e != nil; e = e.next {
+		kn, vn := &yaml.Node{}, &yaml.Node{}
+		if err := kn.Encode(e.key); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err := vn.Encode(e.value); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		node.Content = append(node.Content, kn, vn)
+	}
+	return node, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, entries are added in order of the keys.
+// Existing entries are kept, null is ignored.
+func (m *OrderedMap[K, V]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return errors.Errorf("line %d: expected YAML mapping", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var key K
+		if err := node.Content[i].Decode(&key); err != nil {
+			return errors.WithStack(err)
+		}
+		var value V
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return errors.WithStack(err)
+		}
+		m.Set(key, value)
+	}
+	return nil
+}
+
+// marshalKey returns JSON object key, as encoding/json does for map keys
+func marshalKey(key any) (string, error) {
+	if tm, ok := key.(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		return string(b), nil
+	}
+	rv := reflect.ValueOf(key)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return "", errors.Errorf("unsupported key type: %T", key)
+}
+
+// unmarshalKey decodes JSON object key, as encoding/json does for map keys
+func unmarshalKey(s string, key any) error {
+	if tu, ok := key.(encoding.TextUnmarshaler); ok {
+		return errors.WithStack(tu.UnmarshalText([]byte(s)))
+	}
+	rv := reflect.ValueOf(key).Elem()
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "invalid key %q", s)
+		}
+		rv.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "invalid key %q", s)
+		}
+		rv.SetUint(n)
+		return nil
+	}
+	return errors.Errorf("unsupported key type: %s", rv.Type())
+}
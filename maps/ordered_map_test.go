@@ -0,0 +1,293 @@
+package maps_test
+
+import (
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/effective-security/x/maps"
+)
+
+func TestOrderedMap(t *testing.T) {
+	var m maps.OrderedMap[string, int]
+	assert.Equal(t, 0, m.Len())
+	_, _, ok := m.Front()
+	assert.False(t, ok)
+	_, _, ok = m.Back()
+	assert.False(t, ok)
+	assert.False(t, m.Delete("a"))
+	assert.False(t, m.MoveToFront("a"))
+	assert.False(t, m.MoveToBack("a"))
+
+	m.Set("c", 3)
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("a", 10)
+	assert.Equal(t, 3, m.Len())
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+	assert.Equal(t, []int{3, 10, 2}, m.Values())
+
+	v, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 10, v)
+	_, ok = m.Get("x")
+	assert.False(t, ok)
+	assert.True(t, m.Has("b"))
+	assert.False(t, m.Has("x"))
+
+	assert.True(t, m.MoveToFront("b"))
+	assert.Equal(t, []string{"b", "c", "a"}, m.Keys())
+	assert.True(t, m.MoveToFront("b"))
+	assert.True(t, m.MoveToBack("c"))
+	assert.Equal(t, []string{"b", "a", "c"}, m.Keys())
+	assert.True(t, m.MoveToBack("c"))
+
+	k, v, ok := m.Front()
+	assert.True(t, ok)
+	assert.Equal(t, "b", k)
+	assert.Equal(t, 2, v)
+	k, v, ok = m.Back()
+	assert.True(t, ok)
+	assert.Equal(t, "c", k)
+	assert.Equal(t, 3, v)
+
+	c := m.Clone()
+	assert.True(t, m.Delete("a"))
+	assert.Equal(t, []string{"b", "c"}, m.Keys())
+	assert.True(t, m.Delete("b"))
+	assert.True(t, m.Delete("c"))
+	assert.Equal(t, 0, m.Len())
+	assert.Empty(t, m.Keys())
+	assert.Equal(t, []string{"b", "a", "c"}, c.Keys())
+
+	c.Clear()
+	assert.Equal(t, 0, c.Len())
+	c.Set("z", 1)
+	assert.Equal(t, []string{"z"}, c.Keys())
+}
+
+func TestOrderedMap_Iter(t *testing.T) {
+	m := maps.NewOrderedMap[int, string]()
+	for i, s := range []string{"a", "b", "c", "d"} {
+		m.Set(i, s)
+	}
+
+	var keys []int
+	var values []string
+	for k, v := range m.All() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3}, keys)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, values)
+
+	keys = nil
+	for k := range m.Backward() {
+		keys = append(keys, k)
+		if k == 1 {
+			break
+		}
+	}
+	assert.Equal(t, []int{3, 2, 1}, keys)
+
+	// delete during iteration
+	for k := range m.All() {
+		if k%2 == 0 {
+			m.Delete(k)
+		}
+	}
+	assert.Equal(t, []int{1, 3}, m.Keys())
+
+	keys = nil
+	for k := range m.All() {
+		keys = append(keys, k)
+		break
+	}
+	assert.Equal(t, []int{1}, keys)
+}
+
+func TestOrderedMap_IterDeleteOthers(t *testing.T) {
+	newMap := func() *maps.OrderedMap[string, int] {
+		m := maps.NewOrderedMap[string, int]()
+		for i, k := range []string{"a", "b", "c", "d", "e"} {
+			m.Set(k, i)
+		}
+		return m
+	}
+
+	// deleting the next keys does not stop the iteration
+	m := newMap()
+	var keys []string
+	for k := range m.All() {
+		keys = append(keys, k)
+		if k == "a" {
+			m.Delete("b")
+			m.Delete("c")
+		}
+	}
+	assert.Equal(t, []string{"a", "d", "e"}, keys)
+	assert.Equal(t, []string{"a", "d", "e"}, m.Keys())
+
+	m = newMap()
+	keys = nil
+	for k := range m.Backward() {
+		keys = append(keys, k)
+		if k == "e" {
+			m.Delete("d")
+		}
+	}
+	assert.Equal(t, []string{"e", "c", "b", "a"}, keys)
+
+	// delete the next key and the current one
+	m = newMap()
+	keys = nil
+	for k := range m.All() {
+		keys = append(keys, k)
+		if k == "b" {
+			m.Delete("c")
+			m.Delete("b")
+		}
+	}
+	assert.Equal(t, []string{"a", "b", "d", "e"}, keys)
+
+	// a deleted key added again is yielded at the back
+	m = newMap()
+	keys = nil
+	for k := range m.All() {
+		keys = append(keys, k)
+		if k == "a" {
+			m.Delete("b")
+			m.Set("b", 10)
+		}
+	}
+	assert.Equal(t, []string{"a", "c", "d", "e", "b"}, keys)
+
+	// clear stops the iteration
+	m = newMap()
+	keys = nil
+	for k := range m.All() {
+		keys = append(keys, k)
+		m.Clear()
+	}
+	assert.Equal(t, []string{"a"}, keys)
+}
+
+type orderedConfig struct {
+	Name   string                             `json:"name" yaml:"name"`
+	Labels *maps.OrderedMap[string, any]      `json:"labels" yaml:"labels"`
+	Ports  *maps.OrderedMap[uint16, string]   `json:"ports" yaml:"ports"`
+	Hosts  *maps.OrderedMap[netip.Addr, bool] `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+}
+
+func TestOrderedMap_JSON(t *testing.T) {
+	js := `{"name":"svc","labels":{"zone":"b","app":"x","tier":{"a":2,"z":1},"list":[1,"2"]},"ports":{"8080":"http","443":"https"},"hosts":{"10.0.0.2":true,"10.0.0.1":false}}`
+
+	var cfg orderedConfig
+	require.NoError(t, json.Unmarshal([]byte(js), &cfg))
+	assert.Equal(t, []string{"zone", "app", "tier", "list"}, cfg.Labels.Keys())
+	assert.Equal(t, []uint16{8080, 443}, cfg.Ports.Keys())
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("10.0.0.1")}, cfg.Hosts.Keys())
+
+	b, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, js, string(b))
+
+	b, err = json.Marshal(maps.NewOrderedMap[string, int]())
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(b))
+
+	// duplicates keep the first position and the last value, null is ignored
+	m := maps.NewOrderedMap[string, int]()
+	m.Set("x", 0)
+	require.NoError(t, json.Unmarshal([]byte(`{"b":1,"a":2,"b":3}`), m))
+	require.NoError(t, json.Unmarshal([]byte(`null`), m))
+	b, err = json.Marshal(m)
+	require.NoError(t, err)
+	assert.Equal(t, `{"x":0,"b":3,"a":2}`, string(b))
+
+	assert.EqualError(t, json.Unmarshal([]byte(`[1]`), m), "expected JSON object, got [")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"a":"x"}`), m), "json: cannot unmarshal string into Go value of type int")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"300":"x"}`), maps.NewOrderedMap[uint8, string]()),
+		`invalid key "300": strconv.ParseUint: parsing "300": value out of range`)
+
+	_, err = json.Marshal(&orderedConfig{Labels: maps.NewOrderedMap[string, any]()})
+	require.NoError(t, err)
+
+	bad := maps.NewOrderedMap[float64, int]()
+	bad.Set(1.5, 1)
+	_, err = json.Marshal(bad)
+	assert.ErrorContains(t, err, "unsupported key type: float64")
+}
+
+func TestOrderedMap_YAML(t *testing.T) {
+	doc := `name: svc
+labels:
+    zone: b
+    app: x
+    tier:
+        a: 2
+        z: 1
+ports:
+    8080: http
+    443: https
+`
+	var cfg orderedConfig
+	require.NoError(t, yaml.Unmarshal([]byte(doc), &cfg))
+	assert.Equal(t, []string{"zone", "app", "tier"}, cfg.Labels.Keys())
+	assert.Equal(t, []uint16{8080, 443}, cfg.Ports.Keys())
+
+	b, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, doc, string(b))
+
+	// JSON and YAML agree on the order
+	js, err := json.Marshal(cfg.Labels)
+	require.NoError(t, err)
+	assert.Equal(t, `{"zone":"b","app":"x","tier":{"a":2,"z":1}}`, string(js))
+
+	m := maps.NewOrderedMap[string, int]()
+	require.NoError(t, yaml.Unmarshal([]byte("b: 1\na: 2\n"), m))
+	require.NoError(t, yaml.Unmarshal([]byte("null"), m))
+	assert.Equal(t, []string{"b", "a"}, m.Keys())
+	assert.EqualError(t, yaml.Unmarshal([]byte("- 1\n"), m), "line 1: expected YAML mapping")
+
+	// nested ordered maps
+	nested := maps.NewOrderedMap[string, *maps.OrderedMap[string, int]]()
+	require.NoError(t, yaml.Unmarshal([]byte("b:\n    z: 1\n    a: 2\na: {}\n"), nested))
+	b, err = yaml.Marshal(nested)
+	require.NoError(t, err)
+	assert.Equal(t, "b:\n    z: 1\n    a: 2\na: {}\n", string(b))
+	js, err = json.Marshal(nested)
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":{"z":1,"a":2},"a":{}}`, string(js))
+	assert.Error(t, yaml.Unmarshal([]byte("a: x\n"), m))
+}
+
+func TestOrderedMap_ValueField(t *testing.T) {
+	type config struct {
+		M maps.OrderedMap[string, int] `json:"m" yaml:"m"`
+	}
+	var c config
+	c.M.Set("b", 2)
+	c.M.Set("a", 1)
+
+	b, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Equal(t, `{"m":{"b":2,"a":1}}`, string(b))
+
+	var c2 config
+	require.NoError(t, json.Unmarshal(b, &c2))
+	assert.Equal(t, []string{"b", "a"}, c2.M.Keys())
+
+	y, err := yaml.Marshal(c)
+	require.NoError(t, err)
+	assert.Equal(t, "m:\n    b: 2\n    a: 1\n", string(y))
+
+	b, err = json.Marshal(config{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"m":{}}`, string(b))
+}
@@ -0,0 +1,222 @@
+package maps
+
+import (
+	"hash/maphash"
+	"iter"
+	"sync"
+
+	"github.com/zeebo/xxh3"
+)
+
+// DefaultShards is the number of shards used by NewShardedMap when not specified
+const DefaultShards = 32
+
+// ShardedMap is a concurrency-safe map split into shards,
+// each protected by its own RWMutex.
+// Unlike SyncMap, it performs well for write-heavy workloads,
+// as writers to different shards do not contend.
+// String keys are hashed with XXH3, as values.XXH3HashString64,
+// other keys with maphash.
+// The zero value is an empty map with DefaultShards ready to use.
+type ShardedMap[K comparable, V any] struct {
+	once   sync.Once
+	shards []mapShard[K, V]
+	mask   uint64
+	seed   maphash.Seed
+}
+
+type mapShard[K comparable, V any] struct {
+	lock sync.RWMutex
+	m    map[K]V
+	// pad to a cache line, to avoid false sharing between shards
+	_ [32]byte
+}
+
+// NewShardedMap returns an empty map with the number of shards,
+// rounded up to a power of two. DefaultShards is used if shards is not positive.
+func NewShardedMap[K comparable, V any](shards int) *ShardedMap[K, V] {
+	if shards <= 0 {
+		shards = DefaultShards
+	}
+	m := &ShardedMap[K, V]{}
+	m.once.Do(func() { m.init(shards) })
+	return m
+}
+
+func (m *ShardedMap[K, V]) init(shards int) {
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	m.shards = make([]mapShard[K, V], n)
+	m.mask = uint64(n - 1)
+	m.seed = maphash.MakeSeed()
+	for i := range m.shards {
+		m.shards[i].m = make(map[K]V)
+	}
+}
+
+// getShards returns the shards, and initializes the zero value with DefaultShards
+func (m *ShardedMap[K, V]) getShards() []mapShard[K, V] {
+	m.once.Do(func() { m.init(DefaultShards) })
+	return m.shards
+}
+
+// Shards returns the number of shards
+func (m *ShardedMap[K, V]) Shards() int {
+	return len(m.getShards())
+}
+
+func (m *ShardedMap[K, V]) shard(key K) *mapShard[K, V] {
+	shards := m.getShards()
+	var h uint64
+	if s, ok := any(key).(string); ok {
+		h = xxh3.HashString(s)
+	} else {
+		h = maphash.Comparable(m.seed, key)
+	}
+	return &shards[h&m.mask]
+}
+
+// Load returns the value stored for the key
+func (m *ShardedMap[K, V]) Load(key K) (value V, ok bool) {
+	s := m.shard(key)
+	s.lock.RLock()
+	value, ok = s.m[key]
+	s.lock.RUnlock()
+	return value, ok
+}
+
+// Store sets the value for the key
+func (m *ShardedMap[K, V]) Store(key K, value V) {
+	s := m.shard(key)
+	s.lock.Lock()
+	s.m[key] = value
+	s.lock.Unlock()
+}
+
+// LoadOrStore returns the existing value for the key if present.
+// Otherwise, it stores and returns the given value.
+// The loaded result is true if the value was loaded, false if stored.
+func (m *ShardedMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	s := m.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if v, ok := s.m[key]; ok {
+		return v, true
+	}
+	s.m[key] = value
+	return value, false
+}
+
+// LoadAndDelete deletes the value for the key, and returns the previous value if any
+func (m *ShardedMap[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	s := m.shard(key)
+	s.lock.Lock()
+	value, loaded = s.m[key]
+	if loaded {
+		delete(s.m, key)
+	}
+	s.lock.Unlock()
+	return value, loaded
+}
+
+// Delete deletes the value for the key
+func (m *ShardedMap[K, V]) Delete(key K) {
+	s := m.shard(key)
+	s.lock.Lock()
+	delete(s.m, key)
+	s.lock.Unlock()
+}
+
+// Update atomically sets the value for the key to the result of f,
+// called with the current value and true, or the zero value and false
+// if the key is not present. It returns the new value.
+// f is called under the shard lock, and must not access the map.
+func (m *ShardedMap[K, V]) Update(key K, f func(old V, ok bool) V) V {
+	s := m.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	old, ok := s.m[key]
+	value := f(old, ok)
+	s.m[key] = value
+	return value
+}
+
+// Len returns the number of entries.
+// The result is approximate if the map is modified concurrently.
+func (m *ShardedMap[K, V]) Len() int {
+	n := 0
+	shards := m.getShards()
+	for i := range shards {
+		s := &shards[i]
+		s.lock.RLock()
+		n += len(s.m)
+		s.lock.RUnlock()
+	}
+	return n
+}
+
+// Clear deletes all the entries
+func (m *ShardedMap[K, V]) Clear() {
+	shards := m.getShards()
+	for i := range shards {
+		s := &shards[i]
+		s.lock.Lock()
+		clear(s.m)
+		s.lock.Unlock()
+	}
+}
+
+// Range calls f for each key and value, and stops if f returns false.
+// Each shard is copied under its read lock, and f is called without holding
+// any lock, so f may modify the map. Range does not correspond to a consistent
+// snapshot of the whole map, as sync.Map.Range does not.
+func (m *ShardedMap[K, V]) Range(f func(key K, value V) bool) {
+	for k, v := range m.All() {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// All returns an iterator over key-value pairs, with the same
+// consistency guarantees as Range
+func (m *ShardedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		var keys []K
+		var values []V
+		shards := m.getShards()
+		for i := range shards {
+			s := &shards[i]
+			keys, values = keys[:0], values[:0]
+			s.lock.RLock()
+			for k, v := range s.m {
+				keys = append(keys, k)
+				values = append(values, v)
+			}
+			s.lock.RUnlock()
+
+			for j, k := range keys {
+				if !yield(k, values[j]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Snapshot returns a copy of the entries as a map
+func (m *ShardedMap[K, V]) Snapshot() map[K]V {
+	res := make(map[K]V, m.Len())
+	shards := m.getShards()
+	for i := range shards {
+		s := &shards[i]
+		s.lock.RLock()
+		for k, v := range s.m {
+			res[k] = v
+		}
+		s.lock.RUnlock()
+	}
+	return res
+}
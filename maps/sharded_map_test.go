@@ -0,0 +1,228 @@
+package maps_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/effective-security/x/maps"
+)
+
+func TestShardedMap(t *testing.T) {
+	m := maps.NewShardedMap[string, int](10)
+	assert.Equal(t, 16, m.Shards())
+	assert.Equal(t, maps.DefaultShards, maps.NewShardedMap[string, int](0).Shards())
+
+	m.Store("a", 1)
+	m.Store("b", 2)
+	v, ok := m.Load("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	_, ok = m.Load("c")
+	assert.False(t, ok)
+
+	v, loaded := m.LoadOrStore("a", 10)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, v)
+	v, loaded = m.LoadOrStore("c", 3)
+	assert.False(t, loaded)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, 3, m.Len())
+
+	v, loaded = m.LoadAndDelete("c")
+	assert.True(t, loaded)
+	assert.Equal(t, 3, v)
+	_, loaded = m.LoadAndDelete("c")
+	assert.False(t, loaded)
+
+	m.Delete("b")
+	assert.Equal(t, map[string]int{"a": 1}, m.Snapshot())
+
+	m.Clear()
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestShardedMapZeroValue(t *testing.T) {
+	var m maps.ShardedMap[int, string]
+	assert.Equal(t, 0, m.Len())
+	assert.Empty(t, m.Snapshot())
+
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Go(func() {
+			m.Store(i, strconv.Itoa(i))
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, maps.DefaultShards, m.Shards())
+	assert.Equal(t, 100, m.Len())
+	v, ok := m.Load(42)
+	assert.True(t, ok)
+	assert.Equal(t, "42", v)
+
+	var empty maps.ShardedMap[string, int]
+	_, ok = empty.Load("a")
+	assert.False(t, ok)
+	for range empty.All() {
+		t.Fatal("unexpected entry")
+	}
+}
+
+func TestShardedMapUpdate(t *testing.T) {
+	m := maps.NewShardedMap[int, int](4)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				m.Update(i%10, func(old int, _ bool) int { return old + 1 })
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, m.Len())
+	for k, v := range m.All() {
+		assert.Equal(t, 800, v, "key %d", k)
+	}
+
+	v := m.Update(100, func(old int, ok bool) int {
+		assert.False(t, ok)
+		assert.Equal(t, 0, old)
+		return 5
+	})
+	assert.Equal(t, 5, v)
+}
+
+func TestShardedMapRange(t *testing.T) {
+	m := maps.NewShardedMap[string, int](4)
+	for i := range 100 {
+		m.Store(strconv.Itoa(i), i)
+	}
+
+	sum := 0
+	m.Range(func(k string, v int) bool {
+		assert.Equal(t, strconv.Itoa(v), k)
+		sum += v
+		// modifying the map during iteration does not deadlock
+		m.Delete(k)
+		return true
+	})
+	assert.Equal(t, 4950, sum)
+	assert.Equal(t, 0, m.Len())
+
+	m.Store("a", 1)
+	m.Store("b", 2)
+	count := 0
+	for range m.All() {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+}
+
+const benchKeys = 1024
+
+func benchKeyNames() []string {
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = "session-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func BenchmarkShardedMapStore(b *testing.B) {
+	keys := benchKeyNames()
+	m := maps.NewShardedMap[string, int](0)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			m.Store(keys[i%benchKeys], i)
+			i++
+		}
+	})
+}
+
+func BenchmarkSyncMapStore(b *testing.B) {
+	keys := benchKeyNames()
+	m := maps.SyncMap[string, int]{}
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			m.Store(keys[i%benchKeys], i)
+			i++
+		}
+	})
+}
+
+func BenchmarkShardedMapUpdate(b *testing.B) {
+	keys := benchKeyNames()
+	m := maps.NewShardedMap[string, int](0)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			m.Update(keys[i%benchKeys], func(old int, _ bool) int { return old + 1 })
+			i++
+		}
+	})
+}
+
+func BenchmarkSyncMapUpdate(b *testing.B) {
+	keys := benchKeyNames()
+	m := maps.SyncMap[string, int]{}
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := keys[i%benchKeys]
+			for {
+				old, ok := m.Load(key)
+				if !ok {
+					if _, loaded := m.LoadOrStore(key, 1); !loaded {
+						break
+					}
+					continue
+				}
+				if m.CompareAndSwap(key, old, old+1) {
+					break
+				}
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkShardedMapLoad(b *testing.B) {
+	keys := benchKeyNames()
+	m := maps.NewShardedMap[string, int](0)
+	for i, k := range keys {
+		m.Store(k, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			m.Load(keys[i%benchKeys])
+			i++
+		}
+	})
+}
+
+func BenchmarkSyncMapLoad(b *testing.B) {
+	keys := benchKeyNames()
+	m := maps.SyncMap[string, int]{}
+	for i, k := range keys {
+		m.Store(k, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			m.Load(keys[i%benchKeys])
+			i++
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package slices
+
+import (
+	"iter"
+)
+
+// Pair is a pair of values returned by Zip
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Equal returns true only if the contents of the 2 slices are the same
+func Equal[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx, v := range a {
+		if v != b[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// Each applies a function to each item of the slice
+func Each[T any](items []T, f func(item T)) {
+	for _, v := range items {
+		f(v)
+	}
+}
+
+// Map returns a new slice with the result of f applied to each item
+func Map[T, R any](items []T, f func(item T) R) []R {
+	res := make([]R, len(items))
+	for idx, v := range items {
+		res[idx] = f(v)
+	}
+	return res
+}
+
+// Filter returns a new slice containing only the items that satisfy the predicate
+func Filter[T any](items []T, predicate func(item T) bool) []T {
+	res := make([]T, 0, len(items))
+	for _, v := range items {
+		if predicate(v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Reduce accumulates items of the slice using the provided function
+func Reduce[T, R any](items []T, initial R, f func(acc R, item T) R) R {
+	res := initial
+	for _, v := range items {
+		res = f(res, v)
+	}
+	return res
+}
+
+// FlatMap returns a new slice with the concatenated results of f applied to each item
+func FlatMap[T, R any](items []T, f func(item T) []R) []R {
+	var res []R
+	for _, v := range items {
+		res = append(res, f(v)...)
+	}
+	return res
+}
+
+// Find returns the first item that satisfies the predicate, or false if none found
+func Find[T any](items []T, predicate func(item T) bool) (T, bool) {
+	for _, v := range items {
+		if predicate(v) {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Any returns true if any item satisfies the predicate
+func Any[T any](items []T, predicate func(item T) bool) bool {
+	for _, v := range items {
+		if predicate(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// All returns true if all items satisfy the predicate
+func All[T any](items []T, predicate func(item T) bool) bool {
+	for _, v := range items {
+		if !predicate(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of items that satisfy the predicate
+func Count[T any](items []T, predicate func(item T) bool) int {
+	count := 0
+	for _, v := range items {
+		if predicate(v) {
+			count++
+		}
+	}
+	return count
+}
+
+// Partition splits the slice into the items that satisfy the predicate, and the rest
+func Partition[T any](items []T, predicate func(item T) bool) ([]T, []T) {
+	var matched, rest []T
+	for _, v := range items {
+		if predicate(v) {
+			matched = append(matched, v)
+		} else {
+			rest = append(rest, v)
+		}
+	}
+	return matched, rest
+}
+
+// GroupBy groups items by a key function, the items of each group keep their order
+func GroupBy[T any, K comparable](items []T, keyFunc func(item T) K) map[K][]T {
+	res := make(map[K][]T)
+	for _, v := range items {
+		k := keyFunc(v)
+		res[k] = append(res[k], v)
+	}
+	return res
+}
+
+// IndexBy returns a map of items by a key function,
+// later items take precedence for duplicate keys
+func IndexBy[T any, K comparable](items []T, keyFunc func(item T) K) map[K]T {
+	res := make(map[K]T, len(items))
+	for _, v := range items {
+		res[keyFunc(v)] = v
+	}
+	return res
+}
+
+// Chunk splits the slice into chunks of size, the last chunk may be shorter.
+// The chunks share the underlying array with the slice.
+// It returns nil if size is not positive.
+func Chunk[T any](items []T, size int) [][]T {
+	if size <= 0 {
+		return nil
+	}
+	res := make([][]T, 0, (len(items)+size-1)/size)
+	for chunk := range ChunkSeq(items, size) {
+		res = append(res, chunk)
+	}
+	return res
+}
+
+// Window returns sliding windows of size, advanced by one item.
+// The windows share the underlying array with the slice.
+// It returns nil if size is not positive or greater than the length of the slice.
+func Window[T any](items []T, size int) [][]T {
+	if size <= 0 || size > len(items) {
+		return nil
+	}
+	res := make([][]T, 0, len(items)-size+1)
+	for w := range WindowSeq(items, size) {
+		res = append(res, w)
+	}
+	return res
+}
+
+// Zip returns pairs of items with the same index,
+// the result has the length of the shorter slice
+func Zip[A, B any](a []A, b []B) []Pair[A, B] {
+	n := min(len(a), len(b))
+	res := make([]Pair[A, B], n)
+	for i := range n {
+		res[i] = Pair[A, B]{First: a[i], Second: b[i]}
+	}
+	return res
+}
+
+// Seq returns an iterator over the items of the slice
+func Seq[T any](items []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range items {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Collect returns the items of seq as a slice
+func Collect[T any](seq iter.Seq[T]) []T {
+	var res []T
+	for v := range seq {
+		res = append(res, v)
+	}
+	return res
+}
+
+// MapSeq returns an iterator over the results of f applied to each item of seq
+func MapSeq[T, R any](seq iter.Seq[T], f func(item T) R) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		for v := range seq {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq returns an iterator over the items of seq that satisfy the predicate
+func FilterSeq[T any](seq iter.Seq[T], predicate func(item T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if predicate(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FlatMapSeq returns an iterator over the concatenated results of f applied to each item of seq
+func FlatMapSeq[T, R any](seq iter.Seq[T], f func(item T) []R) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		for v := range seq {
+			for _, r := range f(v) {
+				if !yield(r) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ReduceSeq accumulates items of seq using the provided function
+func ReduceSeq[T, R any](seq iter.Seq[T], initial R, f func(acc R, item T) R) R {
+	res := initial
+	for v := range seq {
+		res = f(res, v)
+	}
+	return res
+}
+
+// ChunkSeq returns an iterator over chunks of size, see Chunk.
+// It yields nothing if size is not positive.
+func ChunkSeq[T any](items []T, size int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		if size <= 0 {
+			return
+		}
+		for i := 0; i < len(items); i += size {
+			end := min(i+size, len(items))
+			if !yield(items[i:end:end]) {
+				return
+			}
+		}
+	}
+}
+
+// WindowSeq returns an iterator over sliding windows of size, see Window
+func WindowSeq[T any](items []T, size int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		if size <= 0 {
+			return
+		}
+		for i := 0; i+size <= len(items); i++ {
+			if !yield(items[i : i+size : i+size]) {
+				return
+			}
+		}
+	}
+}
+
+// ZipSeq returns an iterator over pairs of items with the same index,
+// until one of the slices ends
+func ZipSeq[A, B any](a []A, b []B) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		for i := range min(len(a), len(b)) {
+			if !yield(a[i], b[i]) {
+				return
+			}
+		}
+	}
+}
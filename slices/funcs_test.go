@@ -0,0 +1,134 @@
+package slices
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal([]int{1, 2}, []int{1, 2}))
+	assert.True(t, Equal([]int{}, nil))
+	assert.False(t, Equal([]int{1, 2}, []int{2, 1}))
+	assert.False(t, Equal([]string{"a"}, []string{"a", "b"}))
+}
+
+func TestMapFilterReduce(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, Map(items, strconv.Itoa))
+	assert.Empty(t, Map([]int(nil), strconv.Itoa))
+
+	even := func(v int) bool { return v%2 == 0 }
+	assert.Equal(t, []int{2, 4}, Filter(items, even))
+	assert.Empty(t, Filter(items, func(int) bool { return false }))
+
+	assert.Equal(t, 15, Reduce(items, 0, func(acc, v int) int { return acc + v }))
+	assert.Equal(t, "12345", Reduce(items, "", func(acc string, v int) string { return acc + strconv.Itoa(v) }))
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, FlatMap([]string{"a,b", "", "c,d"}, func(s string) []string {
+		return StringsSafeSplit(s, ",")
+	}))
+
+	v, ok := Find(items, func(v int) bool { return v > 3 })
+	assert.True(t, ok)
+	assert.Equal(t, 4, v)
+	_, ok = Find(items, func(v int) bool { return v > 5 })
+	assert.False(t, ok)
+
+	assert.True(t, Any(items, even))
+	assert.False(t, All(items, even))
+	assert.True(t, All([]int{}, even))
+	assert.Equal(t, 2, Count(items, even))
+
+	sum := 0
+	Each(items, func(v int) { sum += v })
+	assert.Equal(t, 15, sum)
+
+	matched, rest := Partition(items, even)
+	assert.Equal(t, []int{2, 4}, matched)
+	assert.Equal(t, []int{1, 3, 5}, rest)
+}
+
+func TestGroupByIndexBy(t *testing.T) {
+	words := []string{"apple", "avocado", "banana", "blueberry", "cherry"}
+	first := func(s string) byte { return s[0] }
+
+	assert.Equal(t, map[byte][]string{
+		'a': {"apple", "avocado"},
+		'b': {"banana", "blueberry"},
+		'c': {"cherry"},
+	}, GroupBy(words, first))
+
+	assert.Equal(t, map[byte]string{
+		'a': "avocado",
+		'b': "blueberry",
+		'c': "cherry",
+	}, IndexBy(words, first))
+
+	assert.Empty(t, GroupBy([]string(nil), first))
+}
+
+func TestChunkWindow(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, Chunk(items, 2))
+	assert.Equal(t, [][]int{{1, 2, 3, 4, 5}}, Chunk(items, 10))
+	assert.Empty(t, Chunk([]int{}, 2))
+	assert.Nil(t, Chunk(items, 0))
+
+	// appending to a chunk does not overwrite the next one
+	chunks := Chunk(items, 2)
+	_ = append(chunks[0], 10)
+	assert.Equal(t, []int{3, 4}, chunks[1])
+
+	assert.Equal(t, [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}, Window(items, 3))
+	assert.Equal(t, [][]int{{1, 2, 3, 4, 5}}, Window(items, 5))
+	assert.Nil(t, Window(items, 6))
+	assert.Nil(t, Window(items, 0))
+
+	var first [][]int
+	for w := range WindowSeq(items, 2) {
+		first = append(first, w)
+		break
+	}
+	assert.Equal(t, [][]int{{1, 2}}, first)
+	assert.Empty(t, Collect(ChunkSeq(items, -1)))
+	assert.Empty(t, Collect(WindowSeq(items, -1)))
+}
+
+func TestZip(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	values := []int{1, 2}
+
+	assert.Equal(t, []Pair[string, int]{{"a", 1}, {"b", 2}}, Zip(names, values))
+	assert.Empty(t, Zip(names, []int(nil)))
+
+	m := map[string]int{}
+	for k, v := range ZipSeq(names, values) {
+		m[k] = v
+	}
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, m)
+}
+
+func TestSeqPipeline(t *testing.T) {
+	words := []string{"a b", "c", "", "d e f"}
+
+	seq := FlatMapSeq(Seq(words), strings.Fields)
+	upper := MapSeq(FilterSeq(seq, func(s string) bool { return s != "c" }), strings.ToUpper)
+	assert.Equal(t, []string{"A", "B", "D", "E", "F"}, Collect(upper))
+	assert.Equal(t, "ABDEF", ReduceSeq(upper, "", func(acc, s string) string { return acc + s }))
+
+	// early stop
+	var got []string
+	for s := range upper {
+		got = append(got, s)
+		if len(got) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"A", "B"}, got)
+	assert.Nil(t, Collect(Seq([]int(nil))))
+}
@@ -0,0 +1,144 @@
+package slices
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ParallelOptions configures ParallelMapWith and ParallelForEachWith
+type ParallelOptions struct {
+	// Workers is the maximum number of concurrent calls, GOMAXPROCS by default
+	Workers int
+	// CollectErrors calls the function for all items and returns all errors joined,
+	// by default the context is cancelled on the first error, and only that error is returned
+	CollectErrors bool
+}
+
+// ParallelMap returns a new slice with the result of fn applied to each item,
+// with at most workers concurrent calls. The results keep the order of the items.
+// The context passed to fn is cancelled on the first error,
+// which is returned with nil results. Panics in fn are returned as errors.
+func ParallelMap[T, R any](ctx context.Context, in []T, workers int, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	return ParallelMapWith(ctx, in, ParallelOptions{Workers: workers}, fn)
+}
+
+// ParallelMapWith returns a new slice with the result of fn applied to each item,
+// see ParallelMap and ParallelOptions.
+// With CollectErrors, the results of failed items are zero values,
+// and the returned error joins the errors of all failed items in order of the items.
+// The errors are wrapped with the item index.
+// If ctx is done before all items are processed, its error is returned.
+func ParallelMapWith[T, R any](ctx context.Context, in []T, opts ParallelOptions, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(in))
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	res := make([]R, len(in))
+	var itemErrs []error
+	if opts.CollectErrors {
+		itemErrs = make([]error, len(in))
+	}
+	var firstErr error
+	var lock sync.Mutex
+	var processed atomic.Int64
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for i := range indices {
+				if !opts.CollectErrors && ctx.Err() != nil {
+					continue
+				}
+				processed.Add(1)
+				r, err := parallelCall(ctx, fn, in[i], i)
+				if err == nil {
+					res[i] = r
+					continue
+				}
+				if opts.CollectErrors {
+					itemErrs[i] = err
+					continue
+				}
+				lock.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				lock.Unlock()
+			}
+		})
+	}
+
+dispatch:
+	for i := range in {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	var errs []error
+	for _, err := range itemErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if processed.Load() < int64(len(in)) {
+		if !opts.CollectErrors {
+			return nil, errors.WithStack(parent.Err())
+		}
+		errs = append(errs, errors.WithStack(parent.Err()))
+	}
+	if len(errs) > 0 {
+		return res, errors.Join(errs...)
+	}
+	return res, nil
+}
+
+// ParallelForEach calls fn for each item, with at most workers concurrent calls.
+// The context passed to fn is cancelled on the first error, which is returned.
+// Panics in fn are returned as errors.
+func ParallelForEach[T any](ctx context.Context, in []T, workers int, fn func(ctx context.Context, item T) error) error {
+	return ParallelForEachWith(ctx, in, ParallelOptions{Workers: workers}, fn)
+}
+
+// ParallelForEachWith calls fn for each item, see ParallelMapWith
+func ParallelForEachWith[T any](ctx context.Context, in []T, opts ParallelOptions, fn func(ctx context.Context, item T) error) error {
+	_, err := ParallelMapWith(ctx, in, opts, func(ctx context.Context, item T) (struct{}, error) {
+		return struct{}{}, fn(ctx, item)
+	})
+	return err
+}
+
+// parallelCall calls fn, and returns a panic as error
+func parallelCall[T, R any](ctx context.Context, fn func(ctx context.Context, item T) (R, error), item T, i int) (r R, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			var zero R
+			r = zero
+			err = errors.Errorf("item %d: panic: %v", i, p)
+		}
+	}()
+	r, err = fn(ctx, item)
+	if err != nil {
+		return r, errors.Wrapf(err, "item %d", i)
+	}
+	return r, nil
+}
@@ -0,0 +1,135 @@
+package slices
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelMap(t *testing.T) {
+	in := make([]int, 100)
+	for i := range in {
+		in[i] = i
+	}
+
+	var running, maxRunning atomic.Int32
+	res, err := ParallelMap(context.Background(), in, 4, func(_ context.Context, v int) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return strconv.Itoa(v), nil
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 100)
+	for i, s := range res {
+		assert.Equal(t, strconv.Itoa(i), s)
+	}
+	assert.LessOrEqual(t, maxRunning.Load(), int32(4))
+
+	res, err = ParallelMap(context.Background(), []int{}, 0, func(_ context.Context, v int) (string, error) {
+		return "", nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	res, err = ParallelMap(context.Background(), []int{1, 2, 3}, 0, func(_ context.Context, v int) (string, error) {
+		return strconv.Itoa(v * 2), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "4", "6"}, res)
+}
+
+var errTest = errors.New("test error")
+
+func TestParallelMapFirstError(t *testing.T) {
+	in := make([]int, 1000)
+	for i := range in {
+		in[i] = i
+	}
+
+	var calls atomic.Int32
+	res, err := ParallelMap(context.Background(), in, 4, func(ctx context.Context, v int) (int, error) {
+		calls.Add(1)
+		if v == 10 {
+			return 0, errTest
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Millisecond):
+		}
+		return v, nil
+	})
+	require.ErrorIs(t, err, errTest)
+	assert.EqualError(t, err, "item 10: test error")
+	assert.Nil(t, res)
+	// the remaining items are not processed after the error
+	assert.Less(t, calls.Load(), int32(len(in)))
+
+	err = ParallelForEach(context.Background(), in, 2, func(_ context.Context, v int) error {
+		if v == 5 {
+			panic("boom")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "item 5: panic: boom")
+}
+
+func TestParallelMapCollectErrors(t *testing.T) {
+	in := []int{1, 2, 3, 4, 5, 6}
+	res, err := ParallelMapWith(context.Background(), in, ParallelOptions{Workers: 3, CollectErrors: true},
+		func(_ context.Context, v int) (int, error) {
+			switch v {
+			case 2:
+				return 0, errTest
+			case 5:
+				var m map[string]int
+				m["a"] = 1
+			}
+			return v * 10, nil
+		})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errTest)
+	assert.Equal(t, "item 1: test error\nitem 4: panic: assignment to entry in nil map", err.Error())
+	assert.Equal(t, []int{10, 0, 30, 40, 0, 60}, res)
+
+	var count atomic.Int32
+	err = ParallelForEachWith(context.Background(), in, ParallelOptions{CollectErrors: true}, func(_ context.Context, v int) error {
+		count.Add(1)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), count.Load())
+}
+
+func TestParallelMapContext(t *testing.T) {
+	in := make([]int, 100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	_, err := ParallelMap(ctx, in, 2, func(_ context.Context, v int) (int, error) {
+		if calls.Add(1) == 5 {
+			cancel()
+		}
+		return v, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, calls.Load(), int32(len(in)))
+
+	res, err := ParallelMapWith(ctx, in, ParallelOptions{CollectErrors: true}, func(_ context.Context, v int) (int, error) {
+		return v, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, res, len(in))
+}
@@ -0,0 +1,283 @@
+package slices
+
+import (
+	"cmp"
+	"database/sql/driver"
+	"encoding/json"
+	"iter"
+	"reflect"
+	"slices"
+
+	"github.com/cockroachdb/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Set is a set of unique values.
+// It is encoded in JSON, YAML and SQL as a sorted array,
+// values of ordered types are sorted naturally, others by their JSON encoding.
+// A nil Set is empty, and can be read but not modified.
+type Set[T comparable] map[T]struct{}
+
+// NewSet returns a set with the items
+func NewSet[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	s.Add(items...)
+	return s
+}
+
+// Add adds the items to the set
+func (s Set[T]) Add(items ...T) {
+	for _, item := range items {
+		s[item] = struct{}{}
+	}
+}
+
+// Remove removes the items from the set
+func (s Set[T]) Remove(items ...T) {
+	for _, item := range items {
+		delete(s, item)
+	}
+}
+
+// Has returns true if the set contains the item
+func (s Set[T]) Has(item T) bool {
+	_, ok := s[item]
+	return ok
+}
+
+// Len returns the number of items
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Clone returns a copy of the set
+func (s Set[T]) Clone() Set[T] {
+	res := make(Set[T], len(s))
+	for item := range s {
+		res[item] = struct{}{}
+	}
+	return res
+}
+
+// Values returns the items in random order, see SortedValues
+func (s Set[T]) Values() []T {
+	res := make([]T, 0, len(s))
+	for item := range s {
+		res = append(res, item)
+	}
+	return res
+}
+
+// All returns an iterator over the items in random order, see SortedAll
+func (s Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for item := range s {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Union returns a new set with the items of both sets
+func (s Set[T]) Union(o Set[T]) Set[T] {
+	res := make(Set[T], max(len(s), len(o)))
+	for item := range s {
+		res[item] = struct{}{}
+	}
+	for item := range o {
+		res[item] = struct{}{}
+	}
+	return res
+}
+
+// Intersect returns a new set with the items present in both sets
+func (s Set[T]) Intersect(o Set[T]) Set[T] {
+	small, large := s, o
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	res := make(Set[T])
+	for item := range small {
+		if large.Has(item) {
+			res[item] = struct{}{}
+		}
+	}
+	return res
+}
+
+// Difference returns a new set with the items of s not present in o
+func (s Set[T]) Difference(o Set[T]) Set[T] {
+	res := make(Set[T])
+	for item := range s {
+		if !o.Has(item) {
+			res[item] = struct{}{}
+		}
+	}
+	return res
+}
+
+// SymmetricDifference returns a new set with the items present in only one of the sets
+func (s Set[T]) SymmetricDifference(o Set[T]) Set[T] {
+	res := s.Difference(o)
+	for item := range o {
+		if !s.Has(item) {
+			res[item] = struct{}{}
+		}
+	}
+	return res
+}
+
+// IsSubset returns true if all items of s are present in o
+func (s Set[T]) IsSubset(o Set[T]) bool {
+	if len(s) > len(o) {
+		return false
+	}
+	for item := range s {
+		if !o.Has(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset returns true if all items of o are present in s
+func (s Set[T]) IsSuperset(o Set[T]) bool {
+	return o.IsSubset(s)
+}
+
+// Equal returns true if both sets contain the same items
+func (s Set[T]) Equal(o Set[T]) bool {
+	return len(s) == len(o) && s.IsSubset(o)
+}
+
+// SortedValues returns the items of the set in ascending order
+func SortedValues[T cmp.Ordered](s Set[T]) []T {
+	res := s.Values()
+	slices.Sort(res)
+	return res
+}
+
+// SortedAll returns an iterator over the items of the set in ascending order
+func SortedAll[T cmp.Ordered](s Set[T]) iter.Seq[T] {
+	return slices.Values(SortedValues(s))
+}
+
+// sorted returns the items in the encoding order
+func (s Set[T]) sorted() ([]T, error) {
+	res := s.Values()
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.String:
+		slices.SortFunc(res, func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).String(), reflect.ValueOf(b).String())
+		})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		slices.SortFunc(res, func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).Int(), reflect.ValueOf(b).Int())
+		})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		slices.SortFunc(res, func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).Uint(), reflect.ValueOf(b).Uint())
+		})
+	case reflect.Float32, reflect.Float64:
+		slices.SortFunc(res, func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).Float(), reflect.ValueOf(b).Float())
+		})
+	default:
+		keys := make(map[T]string, len(res))
+		for _, item := range res {
+			b, err := json.Marshal(item)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			keys[item] = string(b)
+		}
+		slices.SortFunc(res, func(a, b T) int {
+			return cmp.Compare(keys[a], keys[b])
+		})
+	}
+	return res, nil
+}
+
+// MarshalJSON implements json.Marshaler, the set is encoded as a sorted array
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	items, err := s.sorted()
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, duplicate items are ignored
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return errors.WithStack(err)
+	}
+	if items == nil {
+		*s = nil
+		return nil
+	}
+	*s = NewSet(items...)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler, the set is encoded as a sorted sequence
+func (s Set[T]) MarshalYAML() (any, error) {
+	items, err := s.sorted()
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return items, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, duplicate items are ignored
+func (s *Set[T]) UnmarshalYAML(node *yaml.Node) error {
+	var items []T
+	if err := node.Decode(&items); err != nil {
+		return errors.WithStack(err)
+	}
+	*s = NewSet(items...)
+	return nil
+}
+
+// Scan implements the Scanner interface, the value is a JSON array
+func (s *Set[T]) Scan(value any) error {
+	var b []byte
+	switch vid := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		b = vid
+	case string:
+		b = []byte(vid)
+	default:
+		return errors.Errorf("unsupported scan type: %T", value)
+	}
+
+	if len(b) == 0 {
+		*s = Set[T]{}
+		return nil
+	}
+	return s.UnmarshalJSON(b)
+}
+
+// Value implements the driver Valuer interface, the set is stored as a sorted JSON array
+func (s Set[T]) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	b, err := s.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
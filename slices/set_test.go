@@ -0,0 +1,147 @@
+package slices
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSet(t *testing.T) {
+	s := NewSet(3, 1, 2, 1)
+	assert.Equal(t, 3, s.Len())
+	assert.True(t, s.Has(1))
+	assert.False(t, s.Has(4))
+
+	s.Add(4, 5)
+	s.Remove(5, 6)
+	assert.Equal(t, []int{1, 2, 3, 4}, SortedValues(s))
+	assert.ElementsMatch(t, []int{1, 2, 3, 4}, s.Values())
+
+	var items []int
+	for v := range SortedAll(s) {
+		items = append(items, v)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, items)
+
+	count := 0
+	for range s.All() {
+		count++
+	}
+	assert.Equal(t, 4, count)
+
+	c := s.Clone()
+	c.Add(10)
+	assert.False(t, s.Has(10))
+
+	var empty Set[int]
+	assert.False(t, empty.Has(1))
+	assert.Empty(t, SortedValues(empty))
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := NewSet("a", "b", "c")
+	b := NewSet("b", "c", "d")
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, SortedValues(a.Union(b)))
+	assert.Equal(t, []string{"b", "c"}, SortedValues(a.Intersect(b)))
+	assert.Equal(t, []string{"a"}, SortedValues(a.Difference(b)))
+	assert.Equal(t, []string{"d"}, SortedValues(b.Difference(a)))
+	assert.Equal(t, []string{"a", "d"}, SortedValues(a.SymmetricDifference(b)))
+
+	assert.True(t, NewSet("b").IsSubset(a))
+	assert.False(t, b.IsSubset(a))
+	assert.True(t, a.IsSuperset(NewSet("a", "c")))
+	assert.True(t, Set[string](nil).IsSubset(a))
+	assert.True(t, a.Equal(NewSet("c", "b", "a")))
+	assert.False(t, a.Equal(b))
+
+	// operations do not modify the operands
+	assert.Equal(t, []string{"a", "b", "c"}, SortedValues(a))
+	assert.Empty(t, a.Intersect(nil))
+	assert.True(t, a.Union(nil).Equal(a))
+}
+
+type setItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestSetJSON(t *testing.T) {
+	b, err := json.Marshal(NewSet("b", "c", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, `["a","b","c"]`, string(b))
+
+	b, err = json.Marshal(NewSet(10, -1, 2))
+	require.NoError(t, err)
+	assert.Equal(t, `[-1,2,10]`, string(b))
+
+	b, err = json.Marshal(NewSet(1.5, 0.5))
+	require.NoError(t, err)
+	assert.Equal(t, `[0.5,1.5]`, string(b))
+
+	type level uint16
+	b, err = json.Marshal(NewSet[level](3, 1))
+	require.NoError(t, err)
+	assert.Equal(t, `[1,3]`, string(b))
+
+	b, err = json.Marshal(NewSet(setItem{ID: 2, Name: "b"}, setItem{ID: 1, Name: "a"}))
+	require.NoError(t, err)
+	assert.Equal(t, `[{"id":1,"name":"a"},{"id":2,"name":"b"}]`, string(b))
+
+	b, err = json.Marshal(struct {
+		Tags Set[string] `json:"tags"`
+	}{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"tags":[]}`, string(b))
+
+	var s Set[string]
+	require.NoError(t, json.Unmarshal([]byte(`["x","y","x"]`), &s))
+	assert.Equal(t, []string{"x", "y"}, SortedValues(s))
+	require.NoError(t, json.Unmarshal([]byte(`null`), &s))
+	assert.Nil(t, s)
+	assert.Error(t, json.Unmarshal([]byte(`{}`), &s))
+}
+
+func TestSetYAML(t *testing.T) {
+	b, err := yaml.Marshal(map[string]Set[string]{"tags": NewSet("b", "a")})
+	require.NoError(t, err)
+	assert.Equal(t, "tags:\n    - a\n    - b\n", string(b))
+
+	b, err = yaml.Marshal(map[string]Set[string]{"tags": nil})
+	require.NoError(t, err)
+	assert.Equal(t, "tags: []\n", string(b))
+
+	var v struct {
+		Tags Set[string] `yaml:"tags"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte("tags: [a, b, a]"), &v))
+	assert.Equal(t, []string{"a", "b"}, SortedValues(v.Tags))
+	assert.Error(t, yaml.Unmarshal([]byte("tags: {a: b}"), &v))
+}
+
+func TestSetSQL(t *testing.T) {
+	v, err := NewSet(3, 1, 2).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "[1,2,3]", v)
+
+	v, err = Set[int]{}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	var s Set[int]
+	require.NoError(t, s.Scan("[2,1]"))
+	assert.Equal(t, []int{1, 2}, SortedValues(s))
+	require.NoError(t, s.Scan([]byte("[5]")))
+	assert.Equal(t, []int{5}, SortedValues(s))
+	require.NoError(t, s.Scan([]byte{}))
+	assert.NotNil(t, s)
+	assert.Empty(t, s)
+	require.NoError(t, s.Scan(nil))
+	assert.Nil(t, s)
+
+	assert.EqualError(t, s.Scan(1), "unsupported scan type: int")
+	assert.Error(t, s.Scan(`["a"]`))
+}
@@ -11,40 +11,19 @@ import (
 
 // ByteSlicesEqual returns true only if the contents of the 2 slices are the same
 func ByteSlicesEqual(a, b []byte) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for idx, v := range a {
-		if v != b[idx] {
-			return false
-		}
-	}
-	return true
+	return Equal(a, b)
 }
 
 // StringSlicesEqual returns true only if the contents of the 2 slices are the same
 func StringSlicesEqual(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for idx, v := range a {
-		if v != b[idx] {
-			return false
-		}
-	}
-	return true
+	return Equal(a, b)
 }
 
 // ContainsString returns true if the items slice contains a value equal to item
 // Note that this can end up traversing the entire slice, and so is only really
 // suitable for small slices, for larger data sets, consider using a map instead.
 func ContainsString(items []string, item string) bool {
-	for _, x := range items {
-		if x == item {
-			return true
-		}
-	}
-	return false
+	return Contains(items, item)
 }
 
 // StringContainsOneOf returns true if one of items slice is a substring of specified value.
@@ -130,24 +109,12 @@ func Quoted(items []string) []string {
 // MapStringSlice returns a new slices of strings that is the result of applies mapFn
 // to each string in the input slice.
 func MapStringSlice(items []string, mapFn func(in string) string) []string {
-	res := make([]string, len(items))
-	for idx, v := range items {
-		res[idx] = mapFn(v)
-	}
-	return res
+	return Map(items, mapFn)
 }
 
 // BoolSlicesEqual returns true only if the contents of the 2 slices are the same
 func BoolSlicesEqual(a, b []bool) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for idx, v := range a {
-		if v != b[idx] {
-			return false
-		}
-	}
-	return true
+	return Equal(a, b)
 }
 
 // StringUpto returns the beginning of the string up to `max`
@@ -160,58 +127,22 @@ func StringUpto(str string, maxLen int) string {
 
 // Int64SlicesEqual returns true only if the contents of the 2 slices are the same
 func Int64SlicesEqual(a, b []int64) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for idx, v := range a {
-		if v != b[idx] {
-			return false
-		}
-	}
-	return true
+	return Equal(a, b)
 }
 
 // Uint64SlicesEqual returns true only if the contents of the 2 slices are the same
 func Uint64SlicesEqual(a, b []uint64) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for idx, v := range a {
-		if v != b[idx] {
-			return false
-		}
-	}
-	return true
+	return Equal(a, b)
 }
 
 // Float64SlicesEqual returns true only if the contents of the 2 slices are the same
 func Float64SlicesEqual(a, b []float64) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for idx, v := range a {
-		if v != b[idx] {
-			return false
-		}
-	}
-	return true
+	return Equal(a, b)
 }
 
 // UniqueStrings removes duplicates from the given list
 func UniqueStrings(dups []string) []string {
-	if len(dups) < 2 {
-		return dups
-	}
-	keys := make(map[string]bool)
-	list := []string{}
-
-	for _, entry := range dups {
-		if _, value := keys[entry]; !value {
-			keys[entry] = true
-			list = append(list, entry)
-		}
-	}
-	return list
+	return Deduplicate(dups)
 }
 
 // Deduplicate returns a deduplicated slice.
@@ -255,6 +186,7 @@ func Contains[T comparable](arr []T, val T) bool {
 // - {key} is required;
 // - the first '=' is the separator (also required);
 // -  {value} is optional.
+// Use ParseKeyValues to support quoted values and separators in values.
 var reStringArrayToMap = regexp.MustCompile(`^([^=]+)=(.*)$`)
 
 func StringArrayToMap(arr []string) (map[string]string, error) {
@@ -288,6 +220,7 @@ func HashStrings(values ...string) string {
 }
 
 // StringsSafeSplit splits a string into a slice of strings, removing whitespaces and empty strings.
+// Use SplitQuoted to support quoted fields and escapes.
 func StringsSafeSplit(s, sep string) []string {
 	list := strings.Split(s, sep)
 	res := make([]string, 0, len(list))
@@ -0,0 +1,192 @@
+package slices
+
+import (
+	"cmp"
+	"container/heap"
+	"encoding/binary"
+	"math"
+	"slices"
+
+	"github.com/cockroachdb/errors"
+)
+
+// InsertSorted inserts v into the sorted slice s keeping the order,
+// and returns the slice and true if v was inserted, or false if already present.
+// As with append, the underlying array may be modified, so use the returned slice.
+func InsertSorted[T cmp.Ordered](s []T, v T) ([]T, bool) {
+	i, found := slices.BinarySearch(s, v)
+	if found {
+		return s, false
+	}
+	return slices.Insert(s, i, v), true
+}
+
+// RemoveSorted removes v from the sorted slice s,
+// and returns the slice and true if v was removed, or false if not present.
+// The items of s are shifted in place, so use the returned slice.
+func RemoveSorted[T cmp.Ordered](s []T, v T) ([]T, bool) {
+	i, found := slices.BinarySearch(s, v)
+	if !found {
+		return s, false
+	}
+	return slices.Delete(s, i, i+1), true
+}
+
+// ContainsSorted returns true if the sorted slice s contains v
+func ContainsSorted[T cmp.Ordered](s []T, v T) bool {
+	_, found := slices.BinarySearch(s, v)
+	return found
+}
+
+// MergeSorted merges sorted slices into a new sorted slice, keeping duplicates,
+// in O(n log k) time for k slices with n items in total
+func MergeSorted[T cmp.Ordered](lists ...[]T) []T {
+	total := 0
+	for _, l := range lists {
+		total += len(l)
+	}
+	res := make([]T, 0, total)
+
+	switch len(lists) {
+	case 0:
+		return res
+	case 1:
+		return append(res, lists[0]...)
+	case 2:
+		a, b := lists[0], lists[1]
+		for len(a) > 0 && len(b) > 0 {
+			if b[0] < a[0] {
+				res = append(res, b[0])
+				b = b[1:]
+			} else {
+				res = append(res, a[0])
+				a = a[1:]
+			}
+		}
+		res = append(res, a...)
+		return append(res, b...)
+	}
+
+	h := make(mergeHeap[T], 0, len(lists))
+	for _, l := range lists {
+		if len(l) > 0 {
+			h = append(h, l)
+		}
+	}
+	heap.Init(&h)
+	for len(h) > 0 {
+		l := h[0]
+		res = append(res, l[0])
+		if len(l) > 1 {
+			h[0] = l[1:]
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return res
+}
+
+// mergeHeap is a min-heap of non-empty sorted slices by their first item
+type mergeHeap[T cmp.Ordered] [][]T
+
+func (h mergeHeap[T]) Len() int           { return len(h) }
+func (h mergeHeap[T]) Less(i, j int) bool { return h[i][0] < h[j][0] }
+func (h mergeHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap[T]) Push(x any)        { *h = append(*h, x.([]T)) }
+func (h *mergeHeap[T]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// IntersectSorted returns a new sorted slice with the unique items
+// present in all sorted slices, in linear time
+func IntersectSorted[T cmp.Ordered](lists ...[]T) []T {
+	if len(lists) == 0 {
+		return []T{}
+	}
+	res := UniqueSorted(lists[0])
+	for _, l := range lists[1:] {
+		n := 0
+		i, j := 0, 0
+		for i < len(res) && j < len(l) {
+			switch {
+			case res[i] < l[j]:
+				i++
+			case l[j] < res[i]:
+				j++
+			default:
+				res[n] = res[i]
+				n++
+				i++
+				j++
+			}
+		}
+		res = res[:n]
+	}
+	return res
+}
+
+// UniqueSorted returns a new slice with the unique items of the sorted slice s,
+// in linear time
+func UniqueSorted[T cmp.Ordered](s []T) []T {
+	res := make([]T, 0, len(s))
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// EncodeSortedUint64s returns a compact encoding of the sorted list,
+// as the number of items, the first item and the deltas between the items,
+// all encoded as unsigned varints.
+// It returns an error if the list is not sorted in ascending order.
+func EncodeSortedUint64s(list []uint64) ([]byte, error) {
+	buf := make([]byte, 0, binary.MaxVarintLen64+len(list)*2)
+	buf = binary.AppendUvarint(buf, uint64(len(list)))
+	var prev uint64
+	for i, v := range list {
+		if v < prev {
+			return nil, errors.Errorf("list is not sorted at index %d", i)
+		}
+		buf = binary.AppendUvarint(buf, v-prev)
+		prev = v
+	}
+	return buf, nil
+}
+
+// DecodeSortedUint64s decodes the list encoded by EncodeSortedUint64s
+func DecodeSortedUint64s(data []byte) ([]uint64, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, errors.New("invalid sorted list: count")
+	}
+	data = data[n:]
+	// each item takes at least one byte
+	if count > uint64(len(data)) {
+		return nil, errors.Errorf("invalid sorted list: expected %d items, got %d bytes", count, len(data))
+	}
+
+	list := make([]uint64, 0, count)
+	var prev uint64
+	for i := range count {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errors.Errorf("invalid sorted list: item %d", i)
+		}
+		if delta > math.MaxUint64-prev {
+			return nil, errors.Errorf("invalid sorted list: item %d overflows", i)
+		}
+		prev += delta
+		list = append(list, prev)
+		data = data[n:]
+	}
+	if len(data) > 0 {
+		return nil, errors.Errorf("invalid sorted list: %d trailing bytes", len(data))
+	}
+	return list, nil
+}
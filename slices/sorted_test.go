@@ -0,0 +1,130 @@
+package slices
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertRemoveSorted(t *testing.T) {
+	var s []uint64
+	var ok bool
+	for _, v := range []uint64{5, 1, 3, 5, 9, 1} {
+		s, _ = InsertSorted(s, v)
+	}
+	assert.Equal(t, []uint64{1, 3, 5, 9}, s)
+
+	s, ok = InsertSorted(s, 4)
+	assert.True(t, ok)
+	s, ok = InsertSorted(s, 4)
+	assert.False(t, ok)
+	assert.Equal(t, []uint64{1, 3, 4, 5, 9}, s)
+	assert.True(t, ContainsSorted(s, 9))
+	assert.False(t, ContainsSorted(s, 10))
+
+	s, ok = RemoveSorted(s, 1)
+	assert.True(t, ok)
+	s, ok = RemoveSorted(s, 2)
+	assert.False(t, ok)
+	s, _ = RemoveSorted(s, 9)
+	assert.Equal(t, []uint64{3, 4, 5}, s)
+
+	names, _ := InsertSorted([]string{"a", "c"}, "b")
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+}
+
+func TestMergeSorted(t *testing.T) {
+	assert.Empty(t, MergeSorted[int]())
+	assert.Equal(t, []int{1, 2}, MergeSorted([]int{1, 2}))
+	assert.Equal(t, []int{1, 1, 2, 3, 4}, MergeSorted([]int{1, 3}, []int{1, 2, 4}))
+	assert.Equal(t, []int{0, 1, 2, 3, 3, 5, 6, 7, 8}, MergeSorted(
+		[]int{1, 3, 5},
+		nil,
+		[]int{0, 3, 8},
+		[]int{2, 6, 7},
+	))
+
+	// the input is not modified
+	a := []int{1, 4}
+	res := MergeSorted(a, []int{2})
+	res[0] = 10
+	assert.Equal(t, []int{1, 4}, a)
+
+	rnd := rand.New(rand.NewPCG(1, 2))
+	var lists [][]uint64
+	var all []uint64
+	for range 10 {
+		var l []uint64
+		for range rnd.IntN(50) {
+			l = append(l, rnd.Uint64N(100))
+		}
+		sort.Sort(Uint64s(l))
+		lists = append(lists, l)
+		all = append(all, l...)
+	}
+	sort.Sort(Uint64s(all))
+	assert.Equal(t, all, MergeSorted(lists...))
+}
+
+func TestIntersectUniqueSorted(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 3}, UniqueSorted([]int{1, 1, 2, 3, 3, 3}))
+	assert.Empty(t, UniqueSorted([]int(nil)))
+
+	assert.Equal(t, []int{2, 5}, IntersectSorted([]int{1, 2, 2, 5, 7}, []int{2, 2, 3, 5, 8}))
+	assert.Equal(t, []int{5}, IntersectSorted([]int{1, 2, 5}, []int{2, 5}, []int{5, 9}))
+	assert.Equal(t, []int{1, 2}, IntersectSorted([]int{1, 1, 2}))
+	assert.Empty(t, IntersectSorted([]int{1, 2}, nil))
+	assert.Empty(t, IntersectSorted[int]())
+
+	// the input is not modified
+	a := []int{1, 2, 3}
+	IntersectSorted(a, []int{3})
+	assert.Equal(t, []int{1, 2, 3}, a)
+}
+
+func TestSortedUint64sEncoding(t *testing.T) {
+	lists := [][]uint64{
+		nil,
+		{0},
+		{1, 2, 3, 1000, 1000, 1 << 40},
+		{math.MaxUint64 - 1, math.MaxUint64},
+	}
+	for _, l := range lists {
+		b, err := EncodeSortedUint64s(l)
+		require.NoError(t, err)
+		res, err := DecodeSortedUint64s(b)
+		require.NoError(t, err)
+		assert.Equal(t, len(l), len(res))
+		assert.True(t, Uint64SlicesEqual(l, res), "%v != %v", l, res)
+	}
+
+	// consecutive IDs take one byte per item
+	ids := make([]uint64, 100)
+	for i := range ids {
+		ids[i] = 1<<50 + uint64(i)*3
+	}
+	b, err := EncodeSortedUint64s(ids)
+	require.NoError(t, err)
+	assert.Len(t, b, 1+8+99)
+
+	_, err = EncodeSortedUint64s([]uint64{2, 1})
+	assert.EqualError(t, err, "list is not sorted at index 1")
+
+	_, err = DecodeSortedUint64s(nil)
+	assert.EqualError(t, err, "invalid sorted list: count")
+	_, err = DecodeSortedUint64s([]byte{5, 1})
+	assert.EqualError(t, err, "invalid sorted list: expected 5 items, got 1 bytes")
+	_, err = DecodeSortedUint64s([]byte{2, 1, 0x80})
+	assert.EqualError(t, err, "invalid sorted list: item 1")
+	_, err = DecodeSortedUint64s([]byte{1, 1, 0})
+	assert.EqualError(t, err, "invalid sorted list: 1 trailing bytes")
+
+	b, err = EncodeSortedUint64s([]uint64{math.MaxUint64})
+	require.NoError(t, err)
+	_, err = DecodeSortedUint64s(append(append([]byte{2}, b[1:]...), 1))
+	assert.EqualError(t, err, "invalid sorted list: item 1 overflows")
+}
@@ -0,0 +1,278 @@
+package slices
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/errors"
+)
+
+// KeyValue is a key-value pair returned by ParseKeyValues
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// SyntaxError is returned when a string cannot be split,
+// Offset is the byte offset of the error in the string
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Msg, e.Offset)
+}
+
+// Splitter splits strings into fields, as StringsSafeSplit does,
+// with support of quoting and escaping.
+//
+// Fields are separated by Separator, and whitespace around the fields is removed.
+// Text in double or single quotes is taken as-is, so it can contain separators,
+// quotes and whitespace. A backslash escapes the next character, inside or outside quotes.
+// Quoting can start anywhere in a field: key="a,b" is one field.
+// Empty fields are skipped, unless quoted: a,"",b has three fields.
+type Splitter struct {
+	// Separator between fields, ',' by default
+	Separator rune
+	// KeyValueSeparator between the key and the value in ParseKeyValues, '=' by default
+	KeyValueSeparator rune
+}
+
+// DefaultSplitter is used by SplitQuoted, JoinQuoted, ParseKeyValues and FormatKeyValues
+var DefaultSplitter = Splitter{}
+
+// SplitQuoted splits the string with DefaultSplitter, see Splitter
+func SplitQuoted(s string) ([]string, error) {
+	return DefaultSplitter.Split(s)
+}
+
+// JoinQuoted joins the items with DefaultSplitter, see Splitter.Join
+func JoinQuoted(items []string) string {
+	return DefaultSplitter.Join(items)
+}
+
+// ParseKeyValues parses `key=value,key2="a,b"` with DefaultSplitter,
+// see Splitter.ParseKeyValues
+func ParseKeyValues(s string) ([]KeyValue, error) {
+	return DefaultSplitter.ParseKeyValues(s)
+}
+
+// FormatKeyValues formats the pairs with DefaultSplitter,
+// see Splitter.FormatKeyValues
+func FormatKeyValues(pairs []KeyValue) string {
+	return DefaultSplitter.FormatKeyValues(pairs)
+}
+
+func (p Splitter) separator() rune {
+	if p.Separator == 0 {
+		return ','
+	}
+	return p.Separator
+}
+
+func (p Splitter) keyValueSeparator() rune {
+	if p.KeyValueSeparator == 0 {
+		return '='
+	}
+	return p.KeyValueSeparator
+}
+
+// Split returns the fields of the string, see Splitter.
+// It returns *SyntaxError for an unterminated quote or a trailing backslash.
+func (p Splitter) Split(s string) ([]string, error) {
+	fields, err := p.scan(s, 0)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, len(fields))
+	for i, f := range fields {
+		res[i] = f.value
+	}
+	return res, nil
+}
+
+// Join joins the items with Separator, quoting the items when needed,
+// so that Split returns the same items
+func (p Splitter) Join(items []string) string {
+	var b strings.Builder
+	for i, item := range items {
+		if i > 0 {
+			b.WriteRune(p.separator())
+		}
+		p.writeField(&b, item, 0)
+	}
+	return b.String()
+}
+
+// ParseKeyValues parses `key=value,key2="a,b"` into key-value pairs in order.
+// Each field is split at the first KeyValueSeparator outside of quotes,
+// and the key and the value are unquoted as the fields of Split.
+// Duplicate keys are returned as-is.
+// It returns *SyntaxError for a field without KeyValueSeparator or with an empty key,
+// a quoted empty key, such as `""=x`, is allowed.
+func (p Splitter) ParseKeyValues(s string) ([]KeyValue, error) {
+	kvSep := p.keyValueSeparator()
+	fields, err := p.scan(s, kvSep)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]KeyValue, 0, len(fields))
+	for _, f := range fields {
+		if f.sep < 0 {
+			return nil, errors.WithStack(&SyntaxError{
+				Offset: f.offset,
+				Msg:    fmt.Sprintf("missing %q in %q", kvSep, f.value),
+			})
+		}
+		if f.sep == 0 && !f.quotedKey {
+			return nil, errors.WithStack(&SyntaxError{Offset: f.offset, Msg: "empty key"})
+		}
+		res = append(res, KeyValue{Key: f.value[:f.sep], Value: f.value[f.sep:]})
+	}
+	return res, nil
+}
+
+// FormatKeyValues formats the pairs, quoting the keys and values when needed,
+// so that ParseKeyValues returns the same pairs
+func (p Splitter) FormatKeyValues(pairs []KeyValue) string {
+	var b strings.Builder
+	for i, kv := range pairs {
+		if i > 0 {
+			b.WriteRune(p.separator())
+		}
+		p.writeField(&b, kv.Key, p.keyValueSeparator())
+		b.WriteRune(p.keyValueSeparator())
+		p.writeField(&b, kv.Value, 0)
+	}
+	return b.String()
+}
+
+func (p Splitter) writeField(b *strings.Builder, s string, kvSep rune) {
+	quote := s == "" || strings.TrimSpace(s) != s ||
+		strings.ContainsFunc(s, func(r rune) bool {
+			return r == p.separator() || r == kvSep || r == '"' || r == '\'' || r == '\\'
+		})
+	if !quote {
+		b.WriteString(s)
+		return
+	}
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+}
+
+// field is a scanned field, sep is the index of the key-value separator in value, or -1,
+// and quotedKey is true if the text before the separator was quoted
+type field struct {
+	value     string
+	offset    int
+	sep       int
+	quotedKey bool
+}
+
+// scan returns the fields of s, and finds the first kvSep outside of quotes if not zero
+func (p Splitter) scan(s string, kvSep rune) ([]field, error) {
+	sep := p.separator()
+
+	var fields []field
+	var buf strings.Builder
+	// significant is the length of buf without trailing whitespace
+	significant := 0
+	// started is true after the first non-whitespace character of a key or value
+	started := false
+	quoted := false
+	cur := field{sep: -1}
+
+	finish := func() {
+		value := buf.String()[:significant]
+		if value != "" || quoted || cur.sep >= 0 {
+			cur.value = value
+			fields = append(fields, cur)
+		}
+		buf.Reset()
+		significant = 0
+		started = false
+		quoted = false
+	}
+
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !started && !quoted && significant == 0 && cur.sep < 0 {
+			cur.offset = i
+		}
+
+		switch {
+		case r == sep:
+			finish()
+			cur = field{sep: -1}
+		case r == '\\':
+			next, nsize := utf8.DecodeRuneInString(s[i+size:])
+			if nsize == 0 {
+				return nil, errors.WithStack(&SyntaxError{Offset: i, Msg: "trailing backslash"})
+			}
+			buf.WriteRune(next)
+			significant = buf.Len()
+			started = true
+			size += nsize
+		case r == '"' || r == '\'':
+			end, err := scanQuoted(s, i, r, &buf)
+			if err != nil {
+				return nil, err
+			}
+			significant = buf.Len()
+			started = true
+			quoted = true
+			size = end - i
+		case unicode.IsSpace(r):
+			if started {
+				buf.WriteRune(r)
+			}
+		case r == kvSep && kvSep != 0 && cur.sep < 0:
+			// trim whitespace before the separator, and skip it after
+			value := buf.String()[:significant]
+			buf.Reset()
+			buf.WriteString(value)
+			cur.sep = buf.Len()
+			cur.quotedKey = quoted
+			started = false
+		default:
+			buf.WriteRune(r)
+			significant = buf.Len()
+			started = true
+		}
+		i += size
+	}
+	finish()
+	return fields, nil
+}
+
+// scanQuoted writes the unquoted text starting with the quote at s[start] to buf,
+// and returns the offset after the closing quote
+func scanQuoted(s string, start int, quote rune, buf *strings.Builder) (int, error) {
+	for i := start + 1; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch r {
+		case quote:
+			return i + size, nil
+		case '\\':
+			next, nsize := utf8.DecodeRuneInString(s[i+size:])
+			if nsize == 0 {
+				return 0, errors.WithStack(&SyntaxError{Offset: start, Msg: "unterminated quote"})
+			}
+			buf.WriteRune(next)
+			size += nsize
+		default:
+			buf.WriteRune(r)
+		}
+		i += size
+	}
+	return 0, errors.WithStack(&SyntaxError{Offset: start, Msg: "unterminated quote"})
+}
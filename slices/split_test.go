@@ -0,0 +1,146 @@
+package slices
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitQuoted(t *testing.T) {
+	tcases := []struct {
+		in  string
+		exp []string
+	}{
+		{in: "", exp: []string{}},
+		{in: "a, b ,c", exp: []string{"a", "b", "c"}},
+		{in: "a,,b, ,", exp: []string{"a", "b"}},
+		{in: `a,"b,c",d`, exp: []string{"a", "b,c", "d"}},
+		{in: `" a ",'b"c'`, exp: []string{" a ", `b"c`}},
+		{in: `a,"",b`, exp: []string{"a", "", "b"}},
+		{in: `a\,b,c\\d,\"e`, exp: []string{"a,b", `c\d`, `"e`}},
+		{in: `"a\"b\\c"`, exp: []string{`a"b\c`}},
+		{in: `key="a,b",x=1`, exp: []string{"key=a,b", "x=1"}},
+		{in: `hello world , fo"o b"ar`, exp: []string{"hello world", "foo bar"}},
+		{in: "привет,мир", exp: []string{"привет", "мир"}},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.in, func(t *testing.T) {
+			res, err := SplitQuoted(tc.in)
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, res)
+		})
+	}
+
+	res, err := Splitter{Separator: ';'}.Split(`a,b; "c;d" ;e`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a,b", "c;d", "e"}, res)
+
+	res, err = Splitter{Separator: ' '}.Split(`a  b "c d"`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c d"}, res)
+}
+
+func TestSplitQuotedErrors(t *testing.T) {
+	tcases := []struct {
+		in     string
+		err    string
+		offset int
+	}{
+		{in: `a,"b`, err: "unterminated quote at offset 2", offset: 2},
+		{in: `a,'b\'`, err: "unterminated quote at offset 2", offset: 2},
+		{in: `a,"b\`, err: "unterminated quote at offset 2", offset: 2},
+		{in: `a,b\`, err: "trailing backslash at offset 3", offset: 3},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.in, func(t *testing.T) {
+			_, err := SplitQuoted(tc.in)
+			require.EqualError(t, err, tc.err)
+			var serr *SyntaxError
+			require.True(t, errors.As(err, &serr))
+			assert.Equal(t, tc.offset, serr.Offset)
+		})
+	}
+}
+
+func TestJoinQuoted(t *testing.T) {
+	items := []string{"a", "b,c", "", " d ", `e"f`, `g\h`, "i=j", "k'l"}
+	s := JoinQuoted(items)
+	assert.Equal(t, `a,"b,c",""," d ","e\"f","g\\h",i=j,"k'l"`, s)
+
+	res, err := SplitQuoted(s)
+	require.NoError(t, err)
+	assert.Equal(t, items, res)
+
+	p := Splitter{Separator: ';'}
+	s = p.Join([]string{"a,b", "c;d"})
+	assert.Equal(t, `a,b;"c;d"`, s)
+	res, err = p.Split(s)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a,b", "c;d"}, res)
+
+	assert.Equal(t, "", JoinQuoted(nil))
+}
+
+func TestParseKeyValues(t *testing.T) {
+	res, err := ParseKeyValues(`key=value, key2="a,b", empty=, eq=a=b, "x=y"=z, k = " v ",key=dup`)
+	require.NoError(t, err)
+	assert.Equal(t, []KeyValue{
+		{Key: "key", Value: "value"},
+		{Key: "key2", Value: "a,b"},
+		{Key: "empty", Value: ""},
+		{Key: "eq", Value: "a=b"},
+		{Key: "x=y", Value: "z"},
+		{Key: "k", Value: " v "},
+		{Key: "key", Value: "dup"},
+	}, res)
+
+	res, err = ParseKeyValues(`""=1, '' = 2`)
+	require.NoError(t, err)
+	assert.Equal(t, []KeyValue{{Key: "", Value: "1"}, {Key: "", Value: "2"}}, res)
+
+	res, err = ParseKeyValues("")
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	res, err = Splitter{Separator: ';', KeyValueSeparator: ':'}.ParseKeyValues(`a:1;b:"x;y"`)
+	require.NoError(t, err)
+	assert.Equal(t, []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "x;y"}}, res)
+
+	tcases := []struct {
+		in     string
+		err    string
+		offset int
+	}{
+		{in: `a=1, b`, err: `missing '=' in "b" at offset 5`, offset: 5},
+		{in: `a=1,=2`, err: "empty key at offset 4", offset: 4},
+		{in: `a=1, =2`, err: "empty key at offset 5", offset: 5},
+		{in: `a=1,b="2`, err: "unterminated quote at offset 6", offset: 6},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.in, func(t *testing.T) {
+			_, err := ParseKeyValues(tc.in)
+			require.EqualError(t, err, tc.err)
+			var serr *SyntaxError
+			require.True(t, errors.As(err, &serr))
+			assert.Equal(t, tc.offset, serr.Offset)
+		})
+	}
+}
+
+func TestFormatKeyValues(t *testing.T) {
+	pairs := []KeyValue{
+		{Key: "a", Value: "1"},
+		{Key: "b=c", Value: "x=y"},
+		{Key: "list", Value: "a,b"},
+		{Key: "empty", Value: ""},
+		{Key: "", Value: "x"},
+	}
+	s := FormatKeyValues(pairs)
+	assert.Equal(t, `a=1,"b=c"=x=y,list="a,b",empty="",""=x`, s)
+
+	res, err := ParseKeyValues(s)
+	require.NoError(t, err)
+	assert.Equal(t, pairs, res)
+}
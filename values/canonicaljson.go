@@ -3,6 +3,7 @@ package values
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"sort"
 
 	"github.com/cockroachdb/errors"
@@ -33,7 +34,20 @@ func CanonicalizeJSON(input []byte) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// WriteCanonicalJSON writes canonical JSON representation of v,
+// object keys are sorted and json.Number is written as-is.
 func WriteCanonicalJSON(buf *bytes.Buffer, v any) error {
+	return writeCanonicalJSON(buf, v)
+}
+
+// jsonWriter is implemented by bytes.Buffer and bufio.Writer
+type jsonWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+func writeCanonicalJSON(buf jsonWriter, v any) error {
 	switch x := v.(type) {
 	case map[string]any:
 		keys := make([]string, 0, len(x))
@@ -53,7 +67,7 @@ func WriteCanonicalJSON(buf *bytes.Buffer, v any) error {
 			buf.WriteByte(':')
 
 			// value
-			if err := WriteCanonicalJSON(buf, x[k]); err != nil {
+			if err := writeCanonicalJSON(buf, x[k]); err != nil {
 				return err
 			}
 		}
@@ -66,7 +80,7 @@ func WriteCanonicalJSON(buf *bytes.Buffer, v any) error {
 			if i > 0 {
 				buf.WriteByte(',')
 			}
-			if err := WriteCanonicalJSON(buf, x[i]); err != nil {
+			if err := writeCanonicalJSON(buf, x[i]); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,148 @@
+package values
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"hash"
+	"io"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+)
+
+// CanonicalEncoder writes canonical JSON to io.Writer.
+//
+// EncodeFrom canonicalizes a JSON document read from io.Reader without
+// decoding it into Go values. Object members must be sorted, so each object
+// is held in memory as compact canonical JSON of its members until the object
+// is complete, including arrays nested in the object.
+// Only elements of a top-level array, or of arrays nested in such arrays,
+// are written as soon as they are read, so memory is bounded by the largest
+// element only for documents with a top-level array.
+// A document such as {"records":[...]} is held in memory in full,
+// to stream large exports use a top-level array of records.
+type CanonicalEncoder struct {
+	w    *bufio.Writer
+	form CanonicalForm
+}
+
+// NewCanonicalEncoder returns a new encoder that writes to w
+func NewCanonicalEncoder(w io.Writer, form CanonicalForm) *CanonicalEncoder {
+	return &CanonicalEncoder{
+		w:    bufio.NewWriter(w),
+		form: form,
+	}
+}
+
+// Encode writes canonical JSON representation of v
+func (e *CanonicalEncoder) Encode(v any) error {
+	if err := e.writeValue(e.w, v); err != nil {
+		return err
+	}
+	return errors.WithStack(e.w.Flush())
+}
+
+// EncodeFrom reads a single JSON document from r and writes its canonical representation
+func (e *CanonicalEncoder) EncodeFrom(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if err := e.stream(dec, e.w); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("decode json: unexpected data after top-level value")
+	}
+	return errors.WithStack(e.w.Flush())
+}
+
+func (e *CanonicalEncoder) writeValue(w jsonWriter, v any) error {
+	if e.form == CanonicalJCS {
+		return writeJCS(w, v)
+	}
+	return writeCanonicalJSON(w, v)
+}
+
+func (e *CanonicalEncoder) stream(dec *json.Decoder, w jsonWriter) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return errors.Wrap(err, "decode json")
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return e.writeValue(w, tok)
+	}
+
+	switch delim {
+	case '[':
+		w.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := e.stream(dec, w); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	case '{':
+		members := map[string][]byte{}
+		var keys []string
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return errors.Wrap(err, "decode json")
+			}
+			key := tok.(string)
+
+			var member bytes.Buffer
+			if err := e.stream(dec, &member); err != nil {
+				return err
+			}
+			// the last duplicate wins, as with json.Unmarshal
+			if _, ok := members[key]; !ok {
+				keys = append(keys, key)
+			}
+			members[key] = member.Bytes()
+		}
+
+		if e.form == CanonicalJCS {
+			sort.Slice(keys, func(i, j int) bool {
+				return compareUTF16(keys[i], keys[j]) < 0
+			})
+		} else {
+			sort.Strings(keys)
+		}
+
+		w.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := e.writeValue(w, k); err != nil {
+				return err
+			}
+			w.WriteByte(':')
+			_, _ = w.Write(members[k])
+		}
+		w.WriteByte('}')
+	}
+
+	// consume the closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return errors.Wrap(err, "decode json")
+	}
+	return nil
+}
+
+// HashCanonicalJSON reads a single JSON document from r and returns
+// the digest of its canonical representation, without materializing the output.
+// Any hash.Hash can be used, for example sha256.New() or xxh3.New().
+func HashCanonicalJSON(h hash.Hash, r io.Reader, form CanonicalForm) ([]byte, error) {
+	if err := NewCanonicalEncoder(h, form).EncodeFrom(r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
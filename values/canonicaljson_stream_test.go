@@ -0,0 +1,171 @@
+package values
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/xxh3"
+)
+
+func Test_CanonicalEncoder_EncodeFrom(t *testing.T) {
+	inputs := []string{
+		`{}`,
+		`[]`,
+		`null`,
+		`"<str>"`,
+		`1.50`,
+		`{"b":[2,1,3],"a":["2","1","3"],"c": {"d":[3,4],"e":4,"f":{"g":5,"h":6 } } }`,
+		`[{"b":1,"a":[{"d":true,"c":null}]},[[]],{}]`,
+		`{"€":1,"\r":2,"דּ":3,"1":4,"😀":5,"\u0080":6,"<":7}`,
+		`{"a":1,"b":2,"a":3}`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			exp, err := CanonicalizeJSON([]byte(input))
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = NewCanonicalEncoder(&buf, CanonicalDefault).EncodeFrom(strings.NewReader(input))
+			require.NoError(t, err)
+			assert.Equal(t, string(exp), buf.String())
+
+			exp, err = CanonicalizeJCS([]byte(input))
+			require.NoError(t, err)
+
+			buf.Reset()
+			err = NewCanonicalEncoder(&buf, CanonicalJCS).EncodeFrom(strings.NewReader(input))
+			require.NoError(t, err)
+			assert.Equal(t, string(exp), buf.String())
+		})
+	}
+}
+
+func Test_CanonicalEncoder_Errors(t *testing.T) {
+	tcases := []struct {
+		input string
+		err   string
+	}{
+		{input: ``, err: "decode json: EOF"},
+		{input: `{"a":`, err: "decode json: EOF"},
+		{input: `[1,}`, err: "decode json: invalid character ',' looking for beginning of value"},
+		{input: `{} {}`, err: "decode json: unexpected data after top-level value"},
+		{input: `[1e400]`, err: `invalid number: 1e400: strconv.ParseFloat: parsing "1e400": value out of range`},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.input, func(t *testing.T) {
+			err := NewCanonicalEncoder(io.Discard, CanonicalJCS).EncodeFrom(strings.NewReader(tc.input))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func Test_CanonicalEncoder_Encode(t *testing.T) {
+	m := MapAny{"b": []int{2, 1}, "a": float32(0.1)}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewCanonicalEncoder(&buf, CanonicalDefault).Encode(m))
+	assert.Equal(t, `{"a":0.1,"b":[2,1]}`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, NewCanonicalEncoder(&buf, CanonicalJCS).Encode(m))
+	assert.Equal(t, `{"a":0.10000000149011612,"b":[2,1]}`, buf.String())
+}
+
+func Test_HashCanonicalJSON(t *testing.T) {
+	input := `{"b":[2,1,3],"a":{"d":1.0,"c":"x"}}`
+	exp, err := CanonicalizeJCS([]byte(input))
+	require.NoError(t, err)
+
+	sum, err := HashCanonicalJSON(sha256.New(), strings.NewReader(input), CanonicalJCS)
+	require.NoError(t, err)
+	expSum := sha256.Sum256(exp)
+	assert.Equal(t, expSum[:], sum)
+
+	sum, err = HashCanonicalJSON(xxh3.New(), strings.NewReader(input), CanonicalJCS)
+	require.NoError(t, err)
+	assert.Equal(t, Uint64ToBytes(XXH3Hash64(exp)), sum)
+
+	_, err = HashCanonicalJSON(sha256.New(), strings.NewReader(`[`), CanonicalJCS)
+	assert.EqualError(t, err, "decode json: unexpected end of JSON input")
+}
+
+// failingReader returns an error after limit bytes
+type failingReader struct {
+	r     io.Reader
+	limit int
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.limit <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if len(p) > r.limit {
+		p = p[:r.limit]
+	}
+	n, err := r.r.Read(p)
+	r.limit -= n
+	return n, err
+}
+
+func Test_CanonicalEncoder_StreamsArrays(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("[")
+	for i := range 10000 {
+		if i > 0 {
+			input.WriteString(",")
+		}
+		fmt.Fprintf(&input, `{"id":%d,"name":"item-%d"}`, i, i)
+	}
+	input.WriteString("]")
+
+	// elements are written before the whole array is read
+	var buf bytes.Buffer
+	r := &failingReader{r: strings.NewReader(input.String()), limit: input.Len() / 2}
+	err := NewCanonicalEncoder(&buf, CanonicalJCS).EncodeFrom(r)
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(buf.String(), `[{"id":0,"name":"item-0"},{"id":1,"name":"item-1"}`))
+}
+
+func Test_CanonicalEncoder_NestedArrays(t *testing.T) {
+	var records strings.Builder
+	records.WriteString("[")
+	for i := range 1000 {
+		if i > 0 {
+			records.WriteString(",")
+		}
+		fmt.Fprintf(&records, `{"name":"item-%d","id":%d,"tags":["b","a"]}`, i, i)
+	}
+	records.WriteString("]")
+	input := `{"version":1,"records":` + records.String() + `,"count":1000}`
+
+	var m MapAny
+	require.NoError(t, json.Unmarshal([]byte(input), &m))
+	var expected bytes.Buffer
+	require.NoError(t, WriteJCS(&expected, m))
+
+	var buf bytes.Buffer
+	require.NoError(t, NewCanonicalEncoder(&buf, CanonicalJCS).EncodeFrom(strings.NewReader(input)))
+	assert.Equal(t, expected.String(), buf.String())
+	assert.True(t, strings.HasPrefix(buf.String(), `{"count":1000,"records":[{"id":0,"name":"item-0","tags":["b","a"]},`))
+
+	// arrays nested in objects are buffered until the object is complete
+	buf.Reset()
+	r := &failingReader{r: strings.NewReader(input), limit: len(input) / 2}
+	require.Error(t, NewCanonicalEncoder(&buf, CanonicalJCS).EncodeFrom(r))
+	assert.Empty(t, buf.String())
+
+	// while elements of a top-level array are streamed
+	buf.Reset()
+	r = &failingReader{r: strings.NewReader(`[` + input + `,` + input + `]`), limit: len(input) + 10}
+	require.Error(t, NewCanonicalEncoder(&buf, CanonicalJCS).EncodeFrom(r))
+	assert.NotEmpty(t, buf.String())
+	assert.True(t, strings.HasPrefix(`[`+expected.String(), buf.String()))
+}
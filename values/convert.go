@@ -0,0 +1,415 @@
+package values
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/x/enum"
+	"github.com/effective-security/x/format"
+)
+
+var (
+	// ErrOverflow is returned when a number does not fit in the target type
+	ErrOverflow = errors.New("value out of range")
+	// ErrPrecisionLoss is returned when a number can not be represented exactly in the target type
+	ErrPrecisionLoss = errors.New("precision loss")
+	// ErrInvalidNumber is returned when a string is not a number
+	ErrInvalidNumber = errors.New("invalid number")
+	// ErrUnsupportedType is returned when a value of the type can not be converted to a number
+	ErrUnsupportedType = errors.New("unsupported type")
+)
+
+// ToInt returns the value as int, see ToInt64
+func ToInt(v any) (int, error) {
+	i, err := toSigned(v, strconv.IntSize, "int")
+	return int(i), err
+}
+
+// ToInt32 returns the value as int32, see ToInt64
+func ToInt32(v any) (int32, error) {
+	i, err := toSigned(v, 32, "int32")
+	return int32(i), err
+}
+
+// ToInt64 returns the value as int64.
+// Unlike Int64, it returns an error instead of truncating or wrapping the value:
+// ErrOverflow if the value does not fit in int64,
+// ErrPrecisionLoss if the value has a fractional part,
+// ErrInvalidNumber if a string is not a number,
+// and ErrUnsupportedType for non-numeric types.
+// Strings and json.Number may use 0x, 0o and 0b prefixes, underscores
+// between digits, and exponent notation, for example "1_000", "0xff" or "1e3".
+// A nil value returns 0.
+func ToInt64(v any) (int64, error) {
+	return toSigned(v, 64, "int64")
+}
+
+// ToUInt32 returns the value as uint32, see ToInt64
+func ToUInt32(v any) (uint32, error) {
+	u, err := toUnsigned(v, 32, "uint32")
+	return uint32(u), err
+}
+
+// ToUInt64 returns the value as uint64, see ToInt64.
+// Negative values return ErrOverflow.
+func ToUInt64(v any) (uint64, error) {
+	return toUnsigned(v, 64, "uint64")
+}
+
+// ToFloat32 returns the value as float32, see ToFloat64
+func ToFloat32(v any) (float32, error) {
+	f, err := toFloatBits(v, 32, "float32")
+	return float32(f), err
+}
+
+// ToFloat64 returns the value as float64.
+// ErrPrecisionLoss is returned for integers in the 64-bit range that can not be
+// represented exactly, for example 2^53+1 or "9007199254740993.0",
+// and ErrOverflow for values out of range.
+// Rounding of decimal fractions, such as "0.1", is not reported.
+// A nil value returns 0.
+func ToFloat64(v any) (float64, error) {
+	return toFloatBits(v, 64, "float64")
+}
+
+// integer is a number that fits in int64 or uint64
+type integer struct {
+	neg bool
+	abs uint64
+}
+
+func toSigned(v any, bitSize int, typ string) (int64, error) {
+	n, err := toInteger(v, typ)
+	if err != nil {
+		return 0, err
+	}
+	limit := uint64(1) << (bitSize - 1)
+	if n.neg {
+		if n.abs > limit {
+			return 0, convertError(ErrOverflow, v, typ)
+		}
+		// two's complement handles math.MinInt64
+		return -int64(n.abs-1) - 1, nil
+	}
+	if n.abs >= limit {
+		return 0, convertError(ErrOverflow, v, typ)
+	}
+	return int64(n.abs), nil
+}
+
+func toUnsigned(v any, bitSize int, typ string) (uint64, error) {
+	n, err := toInteger(v, typ)
+	if err != nil {
+		return 0, err
+	}
+	if (n.neg && n.abs != 0) || (bitSize < 64 && n.abs >= uint64(1)<<bitSize) {
+		return 0, convertError(ErrOverflow, v, typ)
+	}
+	return n.abs, nil
+}
+
+func toInteger(v any, typ string) (integer, error) {
+	switch tv := v.(type) {
+	case nil:
+		return integer{}, nil
+	case int:
+		return signedInteger(int64(tv)), nil
+	case int8:
+		return signedInteger(int64(tv)), nil
+	case int16:
+		return signedInteger(int64(tv)), nil
+	case int32:
+		return signedInteger(int64(tv)), nil
+	case int64:
+		return signedInteger(tv), nil
+	case uint:
+		return integer{abs: uint64(tv)}, nil
+	case uint8:
+		return integer{abs: uint64(tv)}, nil
+	case uint16:
+		return integer{abs: uint64(tv)}, nil
+	case uint32:
+		return integer{abs: uint64(tv)}, nil
+	case uint64:
+		return integer{abs: tv}, nil
+	case float32:
+		return floatInteger(float64(tv), v, typ)
+	case float64:
+		return floatInteger(tv, v, typ)
+	case json.Number:
+		return parseInteger(string(tv), v, typ)
+	case string:
+		return parseInteger(tv, v, typ)
+	case enum.ProtoEnum:
+		return signedInteger(int64(tv.Number())), nil
+	default:
+		return integer{}, convertError(ErrUnsupportedType, v, typ)
+	}
+}
+
+func signedInteger(i int64) integer {
+	if i < 0 {
+		// two's complement handles math.MinInt64
+		return integer{neg: true, abs: uint64(^i) + 1}
+	}
+	return integer{abs: uint64(i)}
+}
+
+func floatInteger(f float64, v any, typ string) (integer, error) {
+	switch {
+	case math.IsNaN(f) || math.IsInf(f, 0):
+		return integer{}, convertError(ErrInvalidNumber, v, typ)
+	case f != math.Trunc(f):
+		return integer{}, convertError(ErrPrecisionLoss, v, typ)
+	case math.Abs(f) >= 1<<64:
+		return integer{}, convertError(ErrOverflow, v, typ)
+	}
+	return integer{neg: f < 0, abs: uint64(math.Abs(f))}, nil
+}
+
+func parseInteger(s string, v any, typ string) (integer, error) {
+	if n, ok := parseInt(s); ok {
+		return n, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	switch {
+	case err != nil && errors.Is(err, strconv.ErrRange):
+		return integer{}, convertError(ErrOverflow, v, typ)
+	case err != nil || math.IsNaN(f) || math.IsInf(f, 0):
+		if _, ok := new(big.Int).SetString(normalizeInteger(s), 0); ok {
+			// an integer too big for 64 bits
+			return integer{}, convertError(ErrOverflow, v, typ)
+		}
+		return integer{}, convertError(ErrInvalidNumber, v, typ)
+	case f == 0:
+		mantissa, _, _ := strings.Cut(strings.ToLower(s), "e")
+		if strings.ContainsAny(mantissa, "123456789") {
+			// underflow
+			return integer{}, convertError(ErrPrecisionLoss, v, typ)
+		}
+		return integer{}, nil
+	case math.Abs(f) < 1:
+		return integer{}, convertError(ErrPrecisionLoss, v, typ)
+	case math.Abs(f) >= 1<<64:
+		return integer{}, convertError(ErrOverflow, v, typ)
+	}
+
+	// exponent or fractional notation within 64 bits range,
+	// use exact arithmetic to detect the fractional part
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return integer{}, convertError(ErrInvalidNumber, v, typ)
+	}
+	if !r.IsInt() {
+		return integer{}, convertError(ErrPrecisionLoss, v, typ)
+	}
+	num := r.Num()
+	if num.BitLen() > 64 {
+		return integer{}, convertError(ErrOverflow, v, typ)
+	}
+	return integer{neg: num.Sign() < 0, abs: new(big.Int).Abs(num).Uint64()}, nil
+}
+
+// parseInt parses an integer that fits in 64 bits
+func parseInt(s string) (integer, bool) {
+	s = normalizeInteger(s)
+	if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return signedInteger(i), true
+	}
+	if u, err := strconv.ParseUint(s, 0, 64); err == nil {
+		return integer{abs: u}, true
+	}
+	return integer{}, false
+}
+
+// normalizeInteger removes leading zeros of a decimal number,
+// so "010" is parsed as 10 and not as octal 8.
+// Use "0o" prefix for octal numbers.
+func normalizeInteger(s string) string {
+	sign := ""
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	if len(s) < 2 || s[0] != '0' || s[1] < '0' || s[1] > '9' {
+		return sign + s
+	}
+	s = strings.TrimLeft(s, "0")
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		s = "0" + s
+	}
+	return sign + s
+}
+
+func toFloatBits(v any, bitSize int, typ string) (float64, error) {
+	switch tv := v.(type) {
+	case nil:
+		return 0, nil
+	case float32:
+		return float64(tv), nil
+	case float64:
+		if bitSize == 32 && !math.IsInf(tv, 0) {
+			if math.Abs(tv) > math.MaxFloat32 {
+				return 0, convertError(ErrOverflow, v, typ)
+			}
+			if isInteger64(tv) && float64(float32(tv)) != tv {
+				return 0, convertError(ErrPrecisionLoss, v, typ)
+			}
+		}
+		return tv, nil
+	case json.Number:
+		return parseFloat(string(tv), bitSize, v, typ)
+	case string:
+		return parseFloat(tv, bitSize, v, typ)
+	}
+
+	n, err := toInteger(v, typ)
+	if err != nil {
+		return 0, err
+	}
+	return integerToFloat(n, bitSize, v, typ)
+}
+
+func integerToFloat(n integer, bitSize int, v any, typ string) (float64, error) {
+	// an integer is exact if its significant bits fit in the mantissa
+	mantissa := 53
+	if bitSize == 32 {
+		mantissa = 24
+	}
+	if n.abs != 0 && bits.Len64(n.abs)-bits.TrailingZeros64(n.abs) > mantissa {
+		return 0, convertError(ErrPrecisionLoss, v, typ)
+	}
+	f := float64(n.abs)
+	if n.neg {
+		f = -f
+	}
+	return f, nil
+}
+
+func parseFloat(s string, bitSize int, v any, typ string) (float64, error) {
+	if n, ok := parseInt(s); ok {
+		return integerToFloat(n, bitSize, v, typ)
+	}
+
+	// integers too big for 64 bits
+	if b, ok := new(big.Int).SetString(normalizeInteger(s), 0); ok {
+		bf := new(big.Float).SetInt(b)
+		var f float64
+		var acc big.Accuracy
+		if bitSize == 32 {
+			var f32 float32
+			f32, acc = bf.Float32()
+			f = float64(f32)
+		} else {
+			f, acc = bf.Float64()
+		}
+		switch {
+		case math.IsInf(f, 0):
+			return 0, convertError(ErrOverflow, v, typ)
+		case acc != big.Exact:
+			return 0, convertError(ErrPrecisionLoss, v, typ)
+		}
+		return f, nil
+	}
+
+	f, err := strconv.ParseFloat(s, bitSize)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, convertError(ErrOverflow, v, typ)
+		}
+		return 0, convertError(ErrInvalidNumber, v, typ)
+	}
+	if isInteger64(f) {
+		// integers in fractional or exponent notation, such as "16777217.0",
+		// must be exact as integers are, rounding of decimal fractions is allowed
+		if r, ok := new(big.Rat).SetString(s); ok && r.IsInt() {
+			mantissa := uint(53)
+			if bitSize == 32 {
+				mantissa = 24
+			}
+			if new(big.Float).SetPrec(mantissa).SetInt(r.Num()).Acc() != big.Exact {
+				return 0, convertError(ErrPrecisionLoss, v, typ)
+			}
+		}
+	}
+	return f, nil
+}
+
+// isInteger64 returns true if f is an integer in the range of 64-bit integers,
+// such values must convert to floats exactly as integers do
+func isInteger64(f float64) bool {
+	return f == math.Trunc(f) && math.Abs(f) < 1<<64
+}
+
+func convertError(err error, v any, typ string) error {
+	switch tv := v.(type) {
+	case string:
+		return errors.Wrapf(err, "cannot convert %q to %s", tv, typ)
+	case json.Number:
+		return errors.Wrapf(err, "cannot convert %q to %s", string(tv), typ)
+	}
+	if errors.Is(err, ErrUnsupportedType) {
+		return errors.Wrapf(err, "cannot convert %T to %s", v, typ)
+	}
+	return errors.Wrapf(err, "cannot convert %v to %s", v, typ)
+}
+
+// StrictMapAny provides typed getters that return an error
+// instead of truncating the value or returning 0 on parse failure.
+// Missing keys and nil values return 0.
+type StrictMapAny MapAny
+
+// Strict returns the map with strict typed getters
+func (c MapAny) Strict() StrictMapAny {
+	return StrictMapAny(c)
+}
+
+// Int will return the named value as an int, see ToInt
+func (c StrictMapAny) Int(k string) (int, error) {
+	v, err := ToInt(c[k])
+	return v, keyError(err, k)
+}
+
+// Int64 will return the named value as an int64, see ToInt64
+func (c StrictMapAny) Int64(k string) (int64, error) {
+	v, err := ToInt64(c[k])
+	return v, keyError(err, k)
+}
+
+// UInt64 will return the named value as an uint64, see ToUInt64
+func (c StrictMapAny) UInt64(k string) (uint64, error) {
+	v, err := ToUInt64(c[k])
+	return v, keyError(err, k)
+}
+
+// Float64 will return the named value as a float64, see ToFloat64
+func (c StrictMapAny) Float64(k string) (float64, error) {
+	v, err := ToFloat64(c[k])
+	return v, keyError(err, k)
+}
+
+// Float32 will return the named value as a float32, see ToFloat32
+func (c StrictMapAny) Float32(k string) (float32, error) {
+	v, err := ToFloat32(c[k])
+	return v, keyError(err, k)
+}
+
+// Time will return the named value as time, see format.TimeParser.
+// Missing keys and nil values return zero time.
+func (c StrictMapAny) Time(k string) (time.Time, error) {
+	v, err := format.DefaultTimeParser.Parse(c[k])
+	return v, keyError(err, k)
+}
+
+func keyError(err error, k string) error {
+	if err == nil {
+		return nil
+	}
+	return errors.Wrapf(err, "key %q", k)
+}
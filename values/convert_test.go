@@ -0,0 +1,240 @@
+package values
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToInt64(t *testing.T) {
+	tcases := []struct {
+		v   any
+		exp int64
+		err string
+	}{
+		{v: nil, exp: 0},
+		{v: 1, exp: 1},
+		{v: int8(-8), exp: -8},
+		{v: uint32(32), exp: 32},
+		{v: int64(math.MinInt64), exp: math.MinInt64},
+		{v: uint64(math.MaxInt64), exp: math.MaxInt64},
+		{v: uint64(math.MaxInt64 + 1), err: "cannot convert 9223372036854775808 to int64: value out of range"},
+		{v: float64(1e3), exp: 1000},
+		{v: float32(-2), exp: -2},
+		{v: 1.5, err: "cannot convert 1.5 to int64: precision loss"},
+		{v: 1e19, err: "cannot convert 1e+19 to int64: value out of range"},
+		{v: -1 << 63, exp: math.MinInt64},
+		{v: math.NaN(), err: "cannot convert NaN to int64: invalid number"},
+		{v: json.Number("42"), exp: 42},
+		{v: json.Number("4.2e1"), exp: 42},
+		{v: json.Number("4.25e1"), err: `cannot convert "4.25e1" to int64: precision loss`},
+		{v: "-9223372036854775808", exp: math.MinInt64},
+		{v: "9223372036854775808", err: `cannot convert "9223372036854775808" to int64: value out of range`},
+		{v: "123456789012345678901234567890", err: `cannot convert "123456789012345678901234567890" to int64: value out of range`},
+		{v: "0xFF", exp: 255},
+		{v: "-0x10", exp: -16},
+		{v: "0b101", exp: 5},
+		{v: "0o17", exp: 15},
+		{v: "010", exp: 10},
+		{v: "-007", exp: -7},
+		{v: "1_000_000", exp: 1000000},
+		{v: "1e3", exp: 1000},
+		{v: "1.0", exp: 1},
+		{v: "9007199254740993.0", exp: 9007199254740993},
+		{v: "0.5", err: `cannot convert "0.5" to int64: precision loss`},
+		{v: "0e10", exp: 0},
+		{v: "1e-400", err: `cannot convert "1e-400" to int64: precision loss`},
+		{v: "1e400", err: `cannot convert "1e400" to int64: value out of range`},
+		{v: "1e19", err: `cannot convert "1e19" to int64: value out of range`},
+		{v: "", err: `cannot convert "" to int64: invalid number`},
+		{v: "abc", err: `cannot convert "abc" to int64: invalid number`},
+		{v: "NaN", err: `cannot convert "NaN" to int64: invalid number`},
+		{v: "1/2", err: `cannot convert "1/2" to int64: invalid number`},
+		{v: true, err: "cannot convert bool to int64: unsupported type"},
+		{v: []any{1}, err: "cannot convert []interface {} to int64: unsupported type"},
+	}
+
+	for _, tc := range tcases {
+		v, err := ToInt64(tc.v)
+		if tc.err != "" {
+			assert.EqualError(t, err, tc.err)
+			assert.Equal(t, int64(0), v)
+			continue
+		}
+		if assert.NoError(t, err, "%v", tc.v) {
+			assert.Equal(t, tc.exp, v, "%v", tc.v)
+		}
+	}
+}
+
+func TestToUnsigned(t *testing.T) {
+	u, err := ToUInt64("18446744073709551615")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), u)
+
+	u, err = ToUInt64(json.Number("0xffff_ffff_ffff_ffff"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), u)
+
+	_, err = ToUInt64("18446744073709551616")
+	assert.EqualError(t, err, `cannot convert "18446744073709551616" to uint64: value out of range`)
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	_, err = ToUInt64(-1)
+	assert.EqualError(t, err, "cannot convert -1 to uint64: value out of range")
+
+	u, err = ToUInt64(-0.0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), u)
+
+	u32, err := ToUInt32(uint64(math.MaxUint32))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(math.MaxUint32), u32)
+
+	_, err = ToUInt32(math.MaxUint32 + 1)
+	assert.EqualError(t, err, "cannot convert 4294967296 to uint32: value out of range")
+
+	i32, err := ToInt32("-2147483648")
+	require.NoError(t, err)
+	assert.Equal(t, int32(math.MinInt32), i32)
+
+	_, err = ToInt32(math.MaxInt32 + 1)
+	assert.EqualError(t, err, "cannot convert 2147483648 to int32: value out of range")
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	i, err := ToInt(json.Number("-12"))
+	require.NoError(t, err)
+	assert.Equal(t, -12, i)
+}
+
+func TestToFloat(t *testing.T) {
+	tcases := []struct {
+		v   any
+		exp float64
+		err string
+	}{
+		{v: nil, exp: 0},
+		{v: 1.5, exp: 1.5},
+		{v: float32(0.5), exp: 0.5},
+		{v: int64(1 << 53), exp: 1 << 53},
+		{v: int64(1<<53 + 1), err: "cannot convert 9007199254740993 to float64: precision loss"},
+		{v: uint64(math.MaxUint64), err: "cannot convert 18446744073709551615 to float64: precision loss"},
+		{v: uint64(1 << 63), exp: 1 << 63},
+		{v: int64(math.MinInt64), exp: math.MinInt64},
+		{v: json.Number("0.1"), exp: 0.1},
+		{v: "-1_000.5", exp: -1000.5},
+		{v: "0x10", exp: 16},
+		{v: "1e308", exp: 1e308},
+		{v: "1e309", err: `cannot convert "1e309" to float64: value out of range`},
+		{v: "9007199254740993", err: `cannot convert "9007199254740993" to float64: precision loss`},
+		{v: "18446744073709551616", exp: 1 << 64},
+		{v: "18446744073709551617", err: `cannot convert "18446744073709551617" to float64: precision loss`},
+		{v: "9007199254740993.0", err: `cannot convert "9007199254740993.0" to float64: precision loss`},
+		{v: "90071992547409930e-1", err: `cannot convert "90071992547409930e-1" to float64: precision loss`},
+		{v: "9007199254740992.0", exp: 1 << 53},
+		{v: "x", err: `cannot convert "x" to float64: invalid number`},
+		{v: struct{}{}, err: "cannot convert struct {} to float64: unsupported type"},
+	}
+
+	for _, tc := range tcases {
+		v, err := ToFloat64(tc.v)
+		if tc.err != "" {
+			assert.EqualError(t, err, tc.err)
+			continue
+		}
+		if assert.NoError(t, err, "%v", tc.v) {
+			assert.Equal(t, tc.exp, v, "%v", tc.v)
+		}
+	}
+
+	f, err := ToFloat32(1 << 24)
+	require.NoError(t, err)
+	assert.Equal(t, float32(1<<24), f)
+
+	_, err = ToFloat32(1<<24 + 1)
+	assert.EqualError(t, err, "cannot convert 16777217 to float32: precision loss")
+	assert.ErrorIs(t, err, ErrPrecisionLoss)
+
+	// integral floats and strings are checked as integers
+	_, err = ToFloat32(float64(1<<24 + 1))
+	assert.EqualError(t, err, "cannot convert 1.6777217e+07 to float32: precision loss")
+	_, err = ToFloat32("16777217.0")
+	assert.EqualError(t, err, `cannot convert "16777217.0" to float32: precision loss`)
+	_, err = ToFloat32(json.Number("1.6777217e7"))
+	assert.EqualError(t, err, `cannot convert "1.6777217e7" to float32: precision loss`)
+	f, err = ToFloat32(float64(1 << 24))
+	require.NoError(t, err)
+	assert.Equal(t, float32(1<<24), f)
+	f, err = ToFloat32("16777216.0")
+	require.NoError(t, err)
+	assert.Equal(t, float32(1<<24), f)
+
+	// decimal fractions are rounded
+	f, err = ToFloat32("16777217.5")
+	require.NoError(t, err)
+	assert.Equal(t, float32(16777218), f)
+	f, err = ToFloat32(0.1)
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.1), f)
+
+	_, err = ToFloat32(1e39)
+	assert.EqualError(t, err, "cannot convert 1e+39 to float32: value out of range")
+
+	_, err = ToFloat32("1e39")
+	assert.EqualError(t, err, `cannot convert "1e39" to float32: value out of range`)
+
+	f, err = ToFloat32("0.1")
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.1), f)
+}
+
+func TestMapAny_Strict(t *testing.T) {
+	var m MapAny
+	require.NoError(t, json.Unmarshal([]byte(`{"big":18446744073709551615,"neg":-1,"frac":1.5,"str":"0x10","bad":"x"}`), &m))
+
+	// lax getters truncate and wrap
+	assert.Equal(t, 1, m.Int("frac"))
+	assert.Equal(t, uint64(math.MaxUint64), m.UInt64("neg"))
+	assert.Equal(t, 0, m.Int("str"))
+
+	s := m.Strict()
+	_, err := s.Int("frac")
+	assert.EqualError(t, err, `key "frac": cannot convert 1.5 to int: precision loss`)
+	_, err = s.UInt64("neg")
+	assert.EqualError(t, err, `key "neg": cannot convert -1 to uint64: value out of range`)
+	_, err = s.Int64("big")
+	assert.EqualError(t, err, `key "big": cannot convert 1.8446744073709552e+19 to int64: value out of range`)
+	_, err = s.Float64("bad")
+	assert.EqualError(t, err, `key "bad": cannot convert "x" to float64: invalid number`)
+	assert.ErrorIs(t, err, ErrInvalidNumber)
+
+	i, err := s.Int("str")
+	require.NoError(t, err)
+	assert.Equal(t, 16, i)
+
+	f, err := s.Float32("frac")
+	require.NoError(t, err)
+	assert.Equal(t, float32(1.5), f)
+
+	i, err = s.Int("missing")
+	require.NoError(t, err)
+	assert.Equal(t, 0, i)
+
+	tm, err := MapAny{"t": "1700000000123"}.Strict().Time("t")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1700000000123), tm.UnixMilli())
+	_, err = MapAny{"t": "x"}.Strict().Time("t")
+	assert.EqualError(t, err, `key "t": unable to parse time "x"`)
+
+	// json.Number keeps precision
+	d := json.NewDecoder(bytes.NewReader([]byte(`{"big":18446744073709551615}`)))
+	d.UseNumber()
+	require.NoError(t, d.Decode(&m))
+	u, err := m.Strict().UInt64("big")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), u)
+}
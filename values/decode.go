@@ -0,0 +1,584 @@
+package values
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/x/format"
+)
+
+// DecodeOptions configures Decode
+type DecodeOptions struct {
+	// WeakTypes enables conversions between compatible representations:
+	// strings to numbers, bools, durations and time (see format.ParseStringTime),
+	// numbers and bools to strings, 0/1 to bool, Unix seconds to time,
+	// and a single value to a one-element slice.
+	WeakTypes bool
+	// DisallowUnknownKeys reports keys that do not match any struct field
+	DisallowUnknownKeys bool
+	// TagName specifies the struct tag used for key names, "json" by default
+	TagName string
+}
+
+// FieldError describes a value that could not be decoded
+type FieldError struct {
+	// Path of the value, in a.b[0].c form
+	Path    string
+	Message string
+}
+
+// Error implements error interface
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// DecodeError is returned by Decode and contains all mismatches
+type DecodeError struct {
+	Errors []FieldError
+}
+
+// Error implements error interface
+func (e *DecodeError) Error() string {
+	list := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		list[i] = fe.Error()
+	}
+	return strings.Join(list, "; ")
+}
+
+// Decode decodes the map into the struct pointed to by val.
+// Unlike To, it does not round-trip through JSON, and instead of silently
+// dropping mismatched values it returns *DecodeError with every mismatch and its path.
+// Fields implementing encoding.TextUnmarshaler are decoded from strings,
+// and fields implementing json.Unmarshaler from the JSON encoding of the value.
+func (c MapAny) Decode(val any, opts DecodeOptions) error {
+	return Decode(c, val, opts)
+}
+
+// Decode decodes src into the value pointed to by val, see MapAny.Decode
+func Decode(src any, val any, opts DecodeOptions) error {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.Errorf("decode target must be a non-nil pointer, got %T", val)
+	}
+	if opts.TagName == "" {
+		opts.TagName = "json"
+	}
+
+	d := &decoder{opts: opts}
+	d.decode("", src, rv.Elem())
+	if len(d.errs) > 0 {
+		return &DecodeError{Errors: d.errs}
+	}
+	return nil
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+type decoder struct {
+	opts DecodeOptions
+	errs []FieldError
+}
+
+func (d *decoder) fail(path, msg string, args ...any) {
+	d.errs = append(d.errs, FieldError{Path: path, Message: fmt.Sprintf(msg, args...)})
+}
+
+func (d *decoder) mismatch(path string, src any, dst reflect.Value) {
+	d.fail(path, "cannot decode %T into %s", src, dst.Type())
+}
+
+func (d *decoder) decode(path string, src any, dst reflect.Value) {
+	if src == nil {
+		dst.SetZero()
+		return
+	}
+	if sv := reflect.ValueOf(src); sv.Kind() == reflect.Pointer {
+		if sv.IsNil() {
+			dst.SetZero()
+			return
+		}
+		if dst.Kind() != reflect.Pointer {
+			d.decode(path, sv.Elem().Interface(), dst)
+			return
+		}
+	}
+
+	switch dst.Type() {
+	case timeType:
+		d.decodeTime(path, src, dst)
+		return
+	case durationType:
+		d.decodeDuration(path, src, dst)
+		return
+	}
+	if d.decodeUnmarshaler(path, src, dst) {
+		return
+	}
+
+	switch dst.Kind() {
+	case reflect.Pointer:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		d.decode(path, src, dst.Elem())
+	case reflect.Interface:
+		sv := reflect.ValueOf(src)
+		if !sv.Type().AssignableTo(dst.Type()) {
+			d.mismatch(path, src, dst)
+			return
+		}
+		dst.Set(sv)
+	case reflect.Bool:
+		d.decodeBool(path, src, dst)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		d.decodeInt(path, src, dst)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		d.decodeUint(path, src, dst)
+	case reflect.Float32, reflect.Float64:
+		d.decodeFloat(path, src, dst)
+	case reflect.String:
+		d.decodeString(path, src, dst)
+	case reflect.Slice, reflect.Array:
+		d.decodeSlice(path, src, dst)
+	case reflect.Map:
+		d.decodeMap(path, src, dst)
+	case reflect.Struct:
+		d.decodeStruct(path, src, dst)
+	default:
+		d.fail(path, "unsupported type %s", dst.Type())
+	}
+}
+
+// decodeUnmarshaler decodes string values with encoding.TextUnmarshaler,
+// and other values with json.Unmarshaler, as encoding/json does.
+// It returns false if dst implements neither.
+func (d *decoder) decodeUnmarshaler(path string, src any, dst reflect.Value) bool {
+	if !dst.CanAddr() {
+		return false
+	}
+	ptr := dst.Addr()
+	if s, ok := src.(string); ok && ptr.Type().Implements(textUnmarshalerType) {
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			d.fail(path, "cannot decode %q into %s: %s", s, dst.Type(), err.Error())
+		}
+		return true
+	}
+	if !ptr.Type().Implements(jsonUnmarshalerType) {
+		return false
+	}
+	data, err := json.Marshal(src)
+	if err == nil {
+		err = ptr.Interface().(json.Unmarshaler).UnmarshalJSON(data)
+	}
+	if err != nil {
+		d.fail(path, "cannot decode %T into %s: %s", src, dst.Type(), err.Error())
+	}
+	return true
+}
+
+func (d *decoder) decodeBool(path string, src any, dst reflect.Value) {
+	switch x := src.(type) {
+	case bool:
+		dst.SetBool(x)
+		return
+	case string:
+		if d.opts.WeakTypes {
+			switch strings.ToLower(x) {
+			case "true", "yes", "1":
+				dst.SetBool(true)
+				return
+			case "false", "no", "0", "":
+				dst.SetBool(false)
+				return
+			}
+			d.fail(path, "invalid bool value %q", x)
+			return
+		}
+	default:
+		if d.opts.WeakTypes {
+			if f, ok := toFloat(src); ok && (f == 0 || f == 1) {
+				dst.SetBool(f == 1)
+				return
+			}
+		}
+	}
+	d.mismatch(path, src, dst)
+}
+
+func (d *decoder) decodeInt(path string, src any, dst reflect.Value) {
+	var i int64
+	switch x := src.(type) {
+	case int, int8, int16, int32, int64:
+		i = reflect.ValueOf(x).Int()
+	case uint, uint8, uint16, uint32, uint64:
+		u := reflect.ValueOf(x).Uint()
+		if u > math.MaxInt64 {
+			d.fail(path, "value %d overflows %s", u, dst.Type())
+			return
+		}
+		i = int64(u)
+	case float32, float64:
+		f := reflect.ValueOf(x).Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			d.fail(path, "value %v cannot be represented as %s", f, dst.Type())
+			return
+		}
+		i = int64(f)
+	case json.Number:
+		v, err := strconv.ParseInt(x.String(), 10, 64)
+		if err != nil {
+			d.fail(path, "invalid integer value %q", x.String())
+			return
+		}
+		i = v
+	case string:
+		if !d.opts.WeakTypes {
+			d.mismatch(path, src, dst)
+			return
+		}
+		v, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64)
+		if err != nil {
+			d.fail(path, "invalid integer value %q", x)
+			return
+		}
+		i = v
+	default:
+		d.mismatch(path, src, dst)
+		return
+	}
+	if dst.OverflowInt(i) {
+		d.fail(path, "value %d overflows %s", i, dst.Type())
+		return
+	}
+	dst.SetInt(i)
+}
+
+func (d *decoder) decodeUint(path string, src any, dst reflect.Value) {
+	var u uint64
+	switch x := src.(type) {
+	case int, int8, int16, int32, int64:
+		i := reflect.ValueOf(x).Int()
+		if i < 0 {
+			d.fail(path, "negative value %d for %s", i, dst.Type())
+			return
+		}
+		u = uint64(i)
+	case uint, uint8, uint16, uint32, uint64:
+		u = reflect.ValueOf(x).Uint()
+	case float32, float64:
+		f := reflect.ValueOf(x).Float()
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+			d.fail(path, "value %v cannot be represented as %s", f, dst.Type())
+			return
+		}
+		u = uint64(f)
+	case json.Number:
+		v, err := strconv.ParseUint(x.String(), 10, 64)
+		if err != nil {
+			d.fail(path, "invalid unsigned integer value %q", x.String())
+			return
+		}
+		u = v
+	case string:
+		if !d.opts.WeakTypes {
+			d.mismatch(path, src, dst)
+			return
+		}
+		v, err := strconv.ParseUint(strings.TrimSpace(x), 10, 64)
+		if err != nil {
+			d.fail(path, "invalid unsigned integer value %q", x)
+			return
+		}
+		u = v
+	default:
+		d.mismatch(path, src, dst)
+		return
+	}
+	if dst.OverflowUint(u) {
+		d.fail(path, "value %d overflows %s", u, dst.Type())
+		return
+	}
+	dst.SetUint(u)
+}
+
+func (d *decoder) decodeFloat(path string, src any, dst reflect.Value) {
+	f, ok := toFloat(src)
+	if !ok {
+		s, isStr := src.(string)
+		if !isStr || !d.opts.WeakTypes {
+			d.mismatch(path, src, dst)
+			return
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			d.fail(path, "invalid number value %q", s)
+			return
+		}
+		f = v
+	}
+	if dst.OverflowFloat(f) {
+		d.fail(path, "value %v overflows %s", f, dst.Type())
+		return
+	}
+	dst.SetFloat(f)
+}
+
+func (d *decoder) decodeString(path string, src any, dst reflect.Value) {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.String {
+		if _, isNumber := src.(json.Number); !isNumber || d.opts.WeakTypes {
+			dst.SetString(sv.String())
+			return
+		}
+	}
+	if d.opts.WeakTypes {
+		switch src.(type) {
+		case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			dst.SetString(String(src))
+			return
+		}
+	}
+	d.mismatch(path, src, dst)
+}
+
+func (d *decoder) decodeTime(path string, src any, dst reflect.Value) {
+	switch x := src.(type) {
+	case time.Time:
+		dst.Set(reflect.ValueOf(x))
+		return
+	case string:
+		if x == "" {
+			dst.SetZero()
+			return
+		}
+		if d.opts.WeakTypes {
+			if t := format.ParseStringTime(x); !t.IsZero() {
+				dst.Set(reflect.ValueOf(t))
+				return
+			}
+		} else if t, err := time.Parse(time.RFC3339Nano, x); err == nil {
+			dst.Set(reflect.ValueOf(t))
+			return
+		}
+		d.fail(path, "invalid time value %q", x)
+		return
+	default:
+		if d.opts.WeakTypes {
+			if _, ok := toFloat(src); ok {
+				if t, err := format.DefaultTimeParser.Parse(src); err == nil {
+					dst.Set(reflect.ValueOf(t))
+					return
+				}
+			}
+		}
+	}
+	d.mismatch(path, src, dst)
+}
+
+func (d *decoder) decodeDuration(path string, src any, dst reflect.Value) {
+	if s, ok := src.(string); ok {
+		if !d.opts.WeakTypes {
+			d.mismatch(path, src, dst)
+			return
+		}
+		v, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil {
+			d.fail(path, "invalid duration value %q", s)
+			return
+		}
+		dst.SetInt(int64(v))
+		return
+	}
+	d.decodeInt(path, src, dst)
+}
+
+func (d *decoder) decodeSlice(path string, src any, dst reflect.Value) {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		if !d.opts.WeakTypes || dst.Kind() != reflect.Slice {
+			d.mismatch(path, src, dst)
+			return
+		}
+		sv = reflect.ValueOf([]any{src})
+	}
+
+	n := sv.Len()
+	if dst.Kind() == reflect.Array {
+		if n > dst.Len() {
+			d.fail(path, "array length %d exceeds %s", n, dst.Type())
+			return
+		}
+		dst.SetZero()
+	} else {
+		dst.Set(reflect.MakeSlice(dst.Type(), n, n))
+	}
+	for i := 0; i < n; i++ {
+		d.decode(fmt.Sprintf("%s[%d]", path, i), sv.Index(i).Interface(), dst.Index(i))
+	}
+}
+
+func (d *decoder) decodeMap(path string, src any, dst reflect.Value) {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() != reflect.Map || sv.Type().Key().Kind() != reflect.String {
+		d.mismatch(path, src, dst)
+		return
+	}
+	if dst.Type().Key().Kind() != reflect.String {
+		d.fail(path, "unsupported map key type %s", dst.Type().Key())
+		return
+	}
+
+	res := reflect.MakeMapWithSize(dst.Type(), sv.Len())
+	for _, k := range sortedMapKeys(sv) {
+		elem := reflect.New(dst.Type().Elem()).Elem()
+		d.decode(joinPath(path, k.String()), sv.MapIndex(k).Interface(), elem)
+		res.SetMapIndex(reflect.ValueOf(k.String()).Convert(dst.Type().Key()), elem)
+	}
+	dst.Set(res)
+}
+
+func (d *decoder) decodeStruct(path string, src any, dst reflect.Value) {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() != reflect.Map || sv.Type().Key().Kind() != reflect.String {
+		d.mismatch(path, src, dst)
+		return
+	}
+
+	fields := structFields(dst.Type(), d.opts.TagName)
+	for _, k := range sortedMapKeys(sv) {
+		key := k.String()
+		idx, ok := fields[key]
+		if !ok {
+			idx, ok = foldField(fields, key)
+		}
+		if !ok {
+			if d.opts.DisallowUnknownKeys {
+				d.fail(joinPath(path, key), "unknown key")
+			}
+			continue
+		}
+
+		field, err := dst.FieldByIndexErr(idx)
+		if err != nil {
+			// nil embedded pointer, allocate it
+			var ok bool
+			if field, ok = fieldByIndexAlloc(dst, idx); !ok {
+				d.fail(joinPath(path, key), "cannot set embedded pointer to unexported struct: %s", field.Type().Elem())
+				continue
+			}
+		}
+		d.decode(joinPath(path, key), sv.MapIndex(k).Interface(), field)
+	}
+}
+
+// structFields returns the field index by key name,
+// fields of embedded structs without a tag name are promoted
+func structFields(typ reflect.Type, tagName string) map[string][]int {
+	res := map[string][]int{}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get(tagName), ",")
+		if name == "-" {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for k, idx := range structFields(ft, tagName) {
+				if _, exists := res[k]; !exists {
+					res[k] = append([]int{i}, idx...)
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		res[name] = []int{i}
+	}
+	return res
+}
+
+// foldField returns the field matching the key case-insensitively, as encoding/json does.
+// If several fields match, the first one in the struct order is returned.
+func foldField(fields map[string][]int, key string) ([]int, bool) {
+	var res []int
+	for name, idx := range fields {
+		if strings.EqualFold(name, key) && (res == nil || slices.Compare(idx, res) < 0) {
+			res = idx
+		}
+	}
+	return res, res != nil
+}
+
+// fieldByIndexAlloc returns the field, allocating nil embedded pointers.
+// It returns false and the embedded pointer if it cannot be set,
+// as a pointer to an unexported struct.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case int, int8, int16, int32, int64:
+		return float64(reflect.ValueOf(x).Int()), true
+	case uint, uint8, uint16, uint32, uint64:
+		return float64(reflect.ValueOf(x).Uint()), true
+	case float32, float64:
+		return reflect.ValueOf(x).Float(), true
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
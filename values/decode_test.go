@@ -0,0 +1,326 @@
+package values
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeBase struct {
+	ID      string `json:"id"`
+	Version int    `json:"version"`
+}
+
+type decodeItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+type decodeTarget struct {
+	decodeBase
+	Name     string            `json:"name"`
+	Count    int32             `json:"count"`
+	Size     uint8             `json:"size"`
+	Ratio    float32           `json:"ratio"`
+	Enabled  bool              `json:"enabled"`
+	Timeout  time.Duration     `json:"timeout"`
+	Created  time.Time         `json:"created"`
+	Updated  *time.Time        `json:"updated"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Items    []decodeItem      `json:"items"`
+	Owner    *decodeItem       `json:"owner"`
+	Extra    any               `json:"extra"`
+	Pair     [2]int            `json:"pair"`
+	Skipped  string            `json:"-"`
+	NoTag    string
+	internal string
+}
+
+func TestDecode(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := MapAny{
+		"id":      "123",
+		"version": json.Number("2"),
+		"name":    "test",
+		"count":   float64(10),
+		"size":    uint64(255),
+		"ratio":   0.5,
+		"enabled": true,
+		"timeout": int64(time.Second),
+		"created": created.Format(time.RFC3339),
+		"updated": &created,
+		"tags":    []any{"a", "b"},
+		"labels":  map[string]any{"k": "v"},
+		"items": []any{
+			MapAny{"name": "one", "price": 1.5},
+			map[string]any{"name": "two", "price": json.Number("2")},
+		},
+		"owner":  MapAny{"name": "own"},
+		"extra":  MapAny{"x": 1},
+		"pair":   []int{1, 2},
+		"notag":  "case insensitive",
+		"-":      "ignored",
+		"nilval": nil,
+	}
+
+	var res decodeTarget
+	require.NoError(t, m.Decode(&res, DecodeOptions{}))
+	assert.Equal(t, decodeTarget{
+		decodeBase: decodeBase{ID: "123", Version: 2},
+		Name:       "test",
+		Count:      10,
+		Size:       255,
+		Ratio:      0.5,
+		Enabled:    true,
+		Timeout:    time.Second,
+		Created:    created,
+		Updated:    &created,
+		Tags:       []string{"a", "b"},
+		Labels:     map[string]string{"k": "v"},
+		Items:      []decodeItem{{Name: "one", Price: 1.5}, {Name: "two", Price: 2}},
+		Owner:      &decodeItem{Name: "own"},
+		Extra:      MapAny{"x": 1},
+		Pair:       [2]int{1, 2},
+		NoTag:      "case insensitive",
+	}, res)
+
+	// nil resets the value
+	require.NoError(t, MapAny{"owner": nil, "tags": nil}.Decode(&res, DecodeOptions{}))
+	assert.Nil(t, res.Owner)
+	assert.Nil(t, res.Tags)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	m := MapAny{
+		"id":      1,
+		"version": "2",
+		"count":   1.5,
+		"size":    300,
+		"enabled": "true",
+		"timeout": "1s",
+		"created": "yesterday",
+		"tags":    []any{"a", 1, "c", true},
+		"items":   []any{MapAny{"name": 1, "price": "free"}, "bad"},
+		"owner":   []any{},
+		"pair":    []int{1, 2, 3},
+		"unknown": 1,
+		"labels":  MapAny{"nested": MapAny{}},
+	}
+
+	var res decodeTarget
+	err := m.Decode(&res, DecodeOptions{DisallowUnknownKeys: true})
+	require.Error(t, err)
+
+	var derr *DecodeError
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, []string{
+		"count: value 1.5 cannot be represented as int32",
+		"created: invalid time value \"yesterday\"",
+		"enabled: cannot decode string into bool",
+		"id: cannot decode int into string",
+		"items[0].name: cannot decode int into string",
+		"items[0].price: cannot decode string into float64",
+		"items[1]: cannot decode string into values.decodeItem",
+		"labels.nested: cannot decode values.MapAny into string",
+		"owner: cannot decode []interface {} into values.decodeItem",
+		"pair: array length 3 exceeds [2]int",
+		"size: value 300 overflows uint8",
+		"tags[1]: cannot decode int into string",
+		"tags[3]: cannot decode bool into string",
+		"timeout: cannot decode string into time.Duration",
+		"unknown: unknown key",
+		"version: cannot decode string into int",
+	}, decodeErrors(derr))
+	assert.True(t, strings.HasPrefix(err.Error(), "count: value 1.5 cannot be represented as int32; created: "))
+
+	assert.EqualError(t, m.Decode(res, DecodeOptions{}), "decode target must be a non-nil pointer, got values.decodeTarget")
+	assert.EqualError(t, Decode(m, (*decodeTarget)(nil), DecodeOptions{}), "decode target must be a non-nil pointer, got *values.decodeTarget")
+}
+
+func TestDecode_WeakTypes(t *testing.T) {
+	m := MapAny{
+		"id":      123,
+		"version": " 2 ",
+		"count":   "-5",
+		"size":    "7",
+		"ratio":   "0.25",
+		"enabled": "yes",
+		"timeout": "1m30s",
+		"created": "2024-01-02",
+		"updated": 1704164645,
+		"tags":    "single",
+		"name":    true,
+	}
+
+	var res decodeTarget
+	require.NoError(t, m.Decode(&res, DecodeOptions{WeakTypes: true}))
+	assert.Equal(t, "123", res.ID)
+	assert.Equal(t, 2, res.Version)
+	assert.Equal(t, int32(-5), res.Count)
+	assert.Equal(t, uint8(7), res.Size)
+	assert.Equal(t, float32(0.25), res.Ratio)
+	assert.True(t, res.Enabled)
+	assert.Equal(t, 90*time.Second, res.Timeout)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), res.Created)
+	require.NotNil(t, res.Updated)
+	assert.Equal(t, int64(1704164645), res.Updated.Unix())
+	assert.Equal(t, []string{"single"}, res.Tags)
+	assert.Equal(t, "true", res.Name)
+
+	// numbers are Unix times as parsed by format.DefaultTimeParser
+	var ms decodeTarget
+	require.NoError(t, MapAny{"created": "1700000000123", "updated": float64(1700000000123)}.Decode(&ms, DecodeOptions{WeakTypes: true}))
+	require.NotNil(t, ms.Updated)
+	assert.Equal(t, int64(1700000000123), ms.Updated.UnixMilli())
+	assert.True(t, ms.Created.Equal(*ms.Updated))
+
+	m = MapAny{
+		"version": "two",
+		"size":    "-1",
+		"ratio":   "x",
+		"enabled": "maybe",
+		"timeout": "soon",
+		"created": "2024/01/02",
+		"count":   "99999999999",
+	}
+	err := m.Decode(&res, DecodeOptions{WeakTypes: true})
+	var derr *DecodeError
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, []string{
+		"count: value 99999999999 overflows int32",
+		"created: invalid time value \"2024/01/02\"",
+		"enabled: invalid bool value \"maybe\"",
+		"ratio: invalid number value \"x\"",
+		"size: invalid unsigned integer value \"-1\"",
+		"timeout: invalid duration value \"soon\"",
+		"version: invalid integer value \"two\"",
+	}, decodeErrors(derr))
+}
+
+func TestDecode_TagName(t *testing.T) {
+	type yamlTarget struct {
+		Name string `yaml:"full_name" json:"name"`
+	}
+
+	var res yamlTarget
+	require.NoError(t, MapAny{"full_name": "yaml"}.Decode(&res, DecodeOptions{TagName: "yaml"}))
+	assert.Equal(t, "yaml", res.Name)
+
+	err := MapAny{"full_name": "json"}.Decode(&res, DecodeOptions{DisallowUnknownKeys: true})
+	assert.EqualError(t, err, "full_name: unknown key")
+}
+
+func decodeErrors(err *DecodeError) []string {
+	list := make([]string, len(err.Errors))
+	for i, e := range err.Errors {
+		list[i] = e.Error()
+	}
+	return list
+}
+
+type decodeInner struct {
+	A int
+}
+
+type DecodeExportedInner struct {
+	C int
+}
+
+type decodeOuter struct {
+	*decodeInner
+	*DecodeExportedInner
+	B int
+}
+
+func TestDecode_UnexportedEmbeddedPointer(t *testing.T) {
+	var o decodeOuter
+	err := MapAny{"A": 1, "B": 2, "C": 3}.Decode(&o, DecodeOptions{})
+	require.Error(t, err)
+	assert.EqualError(t, err, "A: cannot set embedded pointer to unexported struct: values.decodeInner")
+	assert.Nil(t, o.decodeInner)
+	assert.Equal(t, 2, o.B)
+	require.NotNil(t, o.DecodeExportedInner)
+	assert.Equal(t, 3, o.C)
+
+	o = decodeOuter{decodeInner: &decodeInner{}}
+	require.NoError(t, MapAny{"A": 1}.Decode(&o, DecodeOptions{}))
+	assert.Equal(t, 1, o.A)
+}
+
+type decodeLevel int
+
+func (l *decodeLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "info":
+		*l = 1
+	case "debug":
+		*l = 2
+	default:
+		return errors.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+type decodeUnmarshalers struct {
+	Addr  netip.Addr  `json:"addr"`
+	Level decodeLevel `json:"level"`
+	N     *big.Int    `json:"n"`
+	Big   big.Int     `json:"big"`
+}
+
+func TestDecode_Unmarshalers(t *testing.T) {
+	var res decodeUnmarshalers
+	m := MapAny{"addr": "10.0.0.1", "level": "debug", "n": "5", "big": json.Number("12345678901234567890")}
+	require.NoError(t, m.Decode(&res, DecodeOptions{WeakTypes: true}))
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), res.Addr)
+	assert.Equal(t, decodeLevel(2), res.Level)
+	require.NotNil(t, res.N)
+	assert.Equal(t, "5", res.N.String())
+	assert.Equal(t, "12345678901234567890", res.Big.String())
+
+	// numbers are decoded with json.Unmarshaler, and as integers without it
+	require.NoError(t, MapAny{"n": 7, "level": 1}.Decode(&res, DecodeOptions{}))
+	assert.Equal(t, "7", res.N.String())
+	assert.Equal(t, decodeLevel(1), res.Level)
+
+	// the same as To
+	var to decodeUnmarshalers
+	require.NoError(t, MapAny{"addr": "10.0.0.1", "level": "debug", "n": 5}.To(&to))
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), to.Addr)
+	assert.Equal(t, decodeLevel(2), to.Level)
+
+	err := MapAny{"addr": "x", "level": "trace", "n": "five"}.Decode(&res, DecodeOptions{})
+	var derr *DecodeError
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, []string{
+		`addr: cannot decode "x" into netip.Addr: ParseAddr("x"): unable to parse IP`,
+		`level: cannot decode "trace" into values.decodeLevel: unknown level "trace"`,
+		`n: cannot decode "five" into big.Int: math/big: cannot unmarshal "five" into a *big.Int`,
+	}, decodeErrors(derr))
+}
+
+type decodeFold struct {
+	Name  string `json:"Name"`
+	Other string `json:"NAME"`
+	Last  string `json:"name_x"`
+}
+
+func TestDecode_CaseInsensitiveOrder(t *testing.T) {
+	for range 20 {
+		var res decodeFold
+		require.NoError(t, MapAny{"name": "a"}.Decode(&res, DecodeOptions{}))
+		assert.Equal(t, decodeFold{Name: "a"}, res)
+	}
+
+	var res decodeFold
+	require.NoError(t, MapAny{"NAME": "b"}.Decode(&res, DecodeOptions{}))
+	assert.Equal(t, decodeFold{Other: "b"}, res)
+}
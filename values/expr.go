@@ -0,0 +1,811 @@
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/x/format"
+)
+
+// Expr is a compiled filter expression, evaluated against MapAny.
+//
+// The syntax supports:
+// - field paths: status, labels.team;
+// - literals: "string" or 'string', numbers, true, false, null, lists [1, 2];
+// - durations: 500ms, 30s, 15m, 24h, 7d, 2w;
+// - now, the current time (see format.NowFunc), with time arithmetic: now-7d;
+// - comparisons: ==, !=, <, <=, >, >=, in, not in, contains;
+// - logical operators: &&, ||, ! and parentheses.
+//
+// Values are compared by type: numbers numerically, strings lexically,
+// and times as instants, where strings and numbers are converted
+// to time with format.ParseTime when compared with a time,
+// the unit of Unix times is detected as in format.TimeParser.
+// Missing fields evaluate to null.
+//
+// The evaluator has no side effects, it only reads the provided map.
+type Expr struct {
+	src  string
+	root exprNode
+}
+
+// maxExprDepth limits nesting of expressions
+const maxExprDepth = 64
+
+// CompileExpr parses the expression
+func CompileExpr(src string) (*Expr, error) {
+	p := &exprParser{lex: exprLexer{src: src}}
+	p.next()
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// MustCompileExpr parses the expression and panics on error
+func MustCompileExpr(src string) *Expr {
+	e, err := CompileExpr(src)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression against m
+func (e *Expr) Eval(m MapAny) (bool, error) {
+	ctx := &exprContext{m: m, now: format.NowFunc()}
+	v, err := e.root.eval(ctx)
+	if err != nil {
+		return false, err
+	}
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case nil:
+		return false, nil
+	default:
+		return false, errors.Errorf("expression result is not a boolean: %T", v)
+	}
+}
+
+// Match returns true if m matches the expression, evaluation errors do not match
+func (e *Expr) Match(m MapAny) bool {
+	ok, err := e.Eval(m)
+	return err == nil && ok
+}
+
+// Filter returns rows that match the expression
+func (e *Expr) Filter(rows []MapAny) []MapAny {
+	var res []MapAny
+	for _, r := range rows {
+		if e.Match(r) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// lexer
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokDuration
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	num  float64
+	dur  time.Duration
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+type exprLexer struct {
+	src string
+	pos int
+}
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+}
+
+var exprOps = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", ",", "+", "-"}
+
+func (l *exprLexer) next() (token, error) {
+	for l.pos < len(l.src) && unicode.IsSpace(rune(l.src[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case c == '"' || c == '\'':
+		return l.lexString(c)
+	case c >= '0' && c <= '9':
+		return l.lexNumber()
+	case isIdentChar(c, false) && (c < '0' || c > '9'):
+		for l.pos < len(l.src) && isIdentChar(l.src[l.pos], true) {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}, nil
+	}
+
+	for _, op := range exprOps {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokOp, text: op, pos: start}, nil
+		}
+	}
+	return token{}, exprErrorf(start, "unexpected character %q", c)
+}
+
+func isIdentChar(c byte, dot bool) bool {
+	return c == '_' || (dot && c == '.') || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func (l *exprLexer) lexString(quote byte) (token, error) {
+	start := l.pos
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case quote:
+			l.pos++
+			return token{kind: tokString, text: b.String(), pos: start}, nil
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, exprErrorf(l.pos, "unterminated string")
+			}
+			l.pos++
+			switch e := l.src[l.pos]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+		l.pos++
+	}
+	return token{}, exprErrorf(start, "unterminated string")
+}
+
+func (l *exprLexer) lexNumber() (token, error) {
+	start := l.pos
+	for l.pos < len(l.src) && (l.src[l.pos] >= '0' && l.src[l.pos] <= '9' || l.src[l.pos] == '.') {
+		l.pos++
+	}
+	// exponent
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') &&
+		l.pos+1 < len(l.src) && (l.src[l.pos+1] >= '0' && l.src[l.pos+1] <= '9' || l.src[l.pos+1] == '-' || l.src[l.pos+1] == '+') {
+		l.pos += 2
+		for l.pos < len(l.src) && l.src[l.pos] >= '0' && l.src[l.pos] <= '9' {
+			l.pos++
+		}
+	}
+	numText := l.src[start:l.pos]
+	num, err := strconv.ParseFloat(numText, 64)
+	if err != nil {
+		return token{}, exprErrorf(start, "invalid number %q", numText)
+	}
+
+	unitStart := l.pos
+	for l.pos < len(l.src) && isIdentChar(l.src[l.pos], false) {
+		l.pos++
+	}
+	if unitStart == l.pos {
+		return token{kind: tokNumber, text: numText, pos: start, num: num}, nil
+	}
+
+	unit, ok := durationUnits[l.src[unitStart:l.pos]]
+	if !ok {
+		return token{}, exprErrorf(unitStart, "invalid duration unit %q", l.src[unitStart:l.pos])
+	}
+	return token{kind: tokDuration, text: l.src[start:l.pos], pos: start, dur: time.Duration(num * float64(unit))}, nil
+}
+
+// ExprError is returned when an expression cannot be parsed
+type ExprError struct {
+	// Pos is 1-based position in the expression
+	Pos     int
+	Message string
+}
+
+// Error implements error interface
+func (e *ExprError) Error() string {
+	return fmt.Sprintf("parse error at position %d: %s", e.Pos, e.Message)
+}
+
+func exprErrorf(pos int, msg string, args ...any) error {
+	return &ExprError{Pos: pos + 1, Message: fmt.Sprintf(msg, args...)}
+}
+
+// parser
+
+type exprParser struct {
+	lex exprLexer
+	tok token
+	err error
+}
+
+func (p *exprParser) next() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lex.next()
+}
+
+func (p *exprParser) errorf(msg string, args ...any) error {
+	if p.err != nil {
+		return p.err
+	}
+	return exprErrorf(p.tok.pos, msg, args...)
+}
+
+func (p *exprParser) isOp(op string) bool {
+	return p.err == nil && p.tok.kind == tokOp && p.tok.text == op
+}
+
+func (p *exprParser) isKeyword(kw string) bool {
+	return p.err == nil && p.tok.kind == tokIdent && p.tok.text == kw
+}
+
+func (p *exprParser) checkDepth(depth int) error {
+	if depth > maxExprDepth {
+		return p.errorf("expression is nested too deeply")
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr(depth int) (exprNode, error) {
+	if err := p.checkDepth(depth); err != nil {
+		return nil, err
+	}
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd(depth int) (exprNode, error) {
+	left, err := p.parseNot(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.next()
+		right, err := p.parseNot(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot(depth int) (exprNode, error) {
+	if err := p.checkDepth(depth); err != nil {
+		return nil, err
+	}
+	if p.isOp("!") {
+		pos := p.tok.pos
+		p.next()
+		x, err := p.parseNot(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{x: x, pos: pos}, nil
+	}
+	return p.parseComparison(depth)
+}
+
+func (p *exprParser) parseComparison(depth int) (exprNode, error) {
+	left, err := p.parseSum(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	var op string
+	pos := p.tok.pos
+	switch {
+	case p.isOp("=="), p.isOp("!="), p.isOp("<"), p.isOp("<="), p.isOp(">"), p.isOp(">="):
+		op = p.tok.text
+	case p.isKeyword("in"), p.isKeyword("contains"):
+		op = p.tok.text
+	case p.isKeyword("not"):
+		p.next()
+		if !p.isKeyword("in") {
+			return nil, p.errorf("expected \"in\" after \"not\", got %s", p.tok)
+		}
+		op = "not in"
+	default:
+		return left, p.err
+	}
+	p.next()
+
+	right, err := p.parseSum(depth)
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{op: op, left: left, right: right, pos: pos}, nil
+}
+
+func (p *exprParser) parseSum(depth int) (exprNode, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op, pos := p.tok.text, p.tok.pos
+		p.next()
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &arithNode{op: op, left: left, right: right, pos: pos}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary(depth int) (exprNode, error) {
+	if err := p.checkDepth(depth); err != nil {
+		return nil, err
+	}
+	if p.isOp("-") {
+		pos := p.tok.pos
+		p.next()
+		x, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &arithNode{op: "-", left: &literalNode{v: float64(0)}, right: x, pos: pos}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *exprParser) parsePrimary(depth int) (exprNode, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	tok := p.tok
+	switch tok.kind {
+	case tokString:
+		p.next()
+		return &literalNode{v: tok.text}, nil
+	case tokNumber:
+		p.next()
+		return &literalNode{v: tok.num}, nil
+	case tokDuration:
+		p.next()
+		return &literalNode{v: tok.dur}, nil
+	case tokIdent:
+		p.next()
+		switch tok.text {
+		case "true", "false":
+			return &literalNode{v: tok.text == "true"}, nil
+		case "null":
+			return &literalNode{v: nil}, nil
+		case "now":
+			return nowNode{}, nil
+		case "in", "not", "contains":
+			return nil, exprErrorf(tok.pos, "unexpected %s", tok)
+		}
+		path := strings.Split(tok.text, ".")
+		for _, part := range path {
+			if part == "" {
+				return nil, exprErrorf(tok.pos, "invalid field path %s", tok)
+			}
+		}
+		return &fieldNode{path: path}, nil
+	case tokOp:
+		switch tok.text {
+		case "(":
+			p.next()
+			x, err := p.parseOr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if !p.isOp(")") {
+				return nil, p.errorf("expected \")\", got %s", p.tok)
+			}
+			p.next()
+			return x, nil
+		case "[":
+			p.next()
+			list := &listNode{}
+			for !p.isOp("]") {
+				if len(list.items) > 0 {
+					if !p.isOp(",") {
+						return nil, p.errorf("expected \",\" or \"]\", got %s", p.tok)
+					}
+					p.next()
+				}
+				item, err := p.parseSum(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+			}
+			p.next()
+			return list, nil
+		}
+	}
+	return nil, p.errorf("unexpected %s", tok)
+}
+
+// evaluation
+
+type exprContext struct {
+	m   MapAny
+	now time.Time
+}
+
+type exprNode interface {
+	eval(ctx *exprContext) (any, error)
+}
+
+type literalNode struct {
+	v any
+}
+
+func (n *literalNode) eval(_ *exprContext) (any, error) {
+	return n.v, nil
+}
+
+type nowNode struct{}
+
+func (nowNode) eval(ctx *exprContext) (any, error) {
+	return ctx.now, nil
+}
+
+type fieldNode struct {
+	path []string
+}
+
+func (n *fieldNode) eval(ctx *exprContext) (any, error) {
+	m := ctx.m
+	last := len(n.path) - 1
+	if last > 0 {
+		m = m.Extract(n.path[:last]...)
+	}
+	if m == nil {
+		return nil, nil
+	}
+	return normalizeExprValue(m[n.path[last]]), nil
+}
+
+type listNode struct {
+	items []exprNode
+}
+
+func (n *listNode) eval(ctx *exprContext) (any, error) {
+	res := make([]any, len(n.items))
+	for i, item := range n.items {
+		v, err := item.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+type notNode struct {
+	x   exprNode
+	pos int
+}
+
+func (n *notNode) eval(ctx *exprContext) (any, error) {
+	v, err := n.x.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch b := v.(type) {
+	case bool:
+		return !b, nil
+	case nil:
+		return true, nil
+	}
+	return nil, errors.Errorf("position %d: cannot negate %T", n.pos+1, v)
+}
+
+type logicalNode struct {
+	or          bool
+	left, right exprNode
+}
+
+func (n *logicalNode) eval(ctx *exprContext) (any, error) {
+	l, err := evalBool(ctx, n.left)
+	if err != nil {
+		return nil, err
+	}
+	if l == n.or {
+		// short-circuit
+		return l, nil
+	}
+	return evalBool(ctx, n.right)
+}
+
+func evalBool(ctx *exprContext, n exprNode) (bool, error) {
+	v, err := n.eval(ctx)
+	if err != nil {
+		return false, err
+	}
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case nil:
+		return false, nil
+	}
+	return false, errors.Errorf("expected boolean, got %T", v)
+}
+
+type arithNode struct {
+	op          string
+	left, right exprNode
+	pos         int
+}
+
+func (n *arithNode) eval(ctx *exprContext) (any, error) {
+	l, err := n.left.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sign := 1.0
+	if n.op == "-" {
+		sign = -1
+	}
+	switch lv := l.(type) {
+	case float64:
+		if rv, ok := r.(float64); ok {
+			return lv + sign*rv, nil
+		}
+	case time.Duration:
+		if rv, ok := r.(time.Duration); ok {
+			return lv + time.Duration(sign)*rv, nil
+		}
+	case time.Time:
+		if rv, ok := r.(time.Duration); ok {
+			return lv.Add(time.Duration(sign) * rv), nil
+		}
+	}
+	return nil, errors.Errorf("position %d: invalid operation %T %s %T", n.pos+1, l, n.op, r)
+}
+
+type compareNode struct {
+	op          string
+	left, right exprNode
+	pos         int
+}
+
+func (n *compareNode) eval(ctx *exprContext) (any, error) {
+	l, err := n.left.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return exprEqual(l, r), nil
+	case "!=":
+		return !exprEqual(l, r), nil
+	case "in", "not in":
+		list, ok := r.([]any)
+		if !ok && r != nil {
+			return nil, errors.Errorf("position %d: %q requires a list, got %T", n.pos+1, n.op, r)
+		}
+		found := false
+		for _, item := range list {
+			if exprEqual(l, item) {
+				found = true
+				break
+			}
+		}
+		return found == (n.op == "in"), nil
+	case "contains":
+		switch lv := l.(type) {
+		case []any:
+			for _, item := range lv {
+				if exprEqual(item, r) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case string:
+			rs, ok := r.(string)
+			return ok && strings.Contains(lv, rs), nil
+		case nil:
+			return false, nil
+		}
+		return nil, errors.Errorf("position %d: \"contains\" requires a list or string, got %T", n.pos+1, l)
+	}
+
+	c, ok := exprCompare(l, r)
+	if !ok {
+		// values of different types are not ordered
+		return false, nil
+	}
+	switch n.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+// normalizeExprValue converts numbers to float64, maps to MapAny and slices to []any
+func normalizeExprValue(v any) any {
+	switch x := v.(type) {
+	case nil, string, bool, float64, time.Time, time.Duration:
+		return v
+	case *time.Time:
+		if x == nil {
+			return nil
+		}
+		return *x
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32:
+		return Float64(x)
+	case json.Number:
+		f, err := x.Float64()
+		if err != nil {
+			return x.String()
+		}
+		return f
+	case []any:
+		res := make([]any, len(x))
+		for i, item := range x {
+			res[i] = normalizeExprValue(item)
+		}
+		return res
+	}
+	if m, ok := CastMapAny(v); ok {
+		return m
+	}
+	if IsSlice(v) {
+		if _, ok := v.([]byte); !ok {
+			return normalizeExprValue(cloneJSONValue(v))
+		}
+	}
+	return v
+}
+
+func exprEqual(l, r any) bool {
+	if l == nil || r == nil {
+		return l == nil && r == nil
+	}
+	if c, ok := exprCompare(l, r); ok {
+		return c == 0
+	}
+	return jsonEqual(l, r)
+}
+
+// exprCompare compares values of compatible types
+func exprCompare(l, r any) (int, bool) {
+	switch lv := l.(type) {
+	case float64:
+		switch rv := r.(type) {
+		case float64:
+			return compareFloat(lv, rv), true
+		case string:
+			if f, err := strconv.ParseFloat(rv, 64); err == nil {
+				return compareFloat(lv, f), true
+			}
+		case time.Time:
+			if t, err := format.DefaultTimeParser.Parse(lv); err == nil {
+				return t.Compare(rv), true
+			}
+		}
+	case string:
+		switch rv := r.(type) {
+		case string:
+			return strings.Compare(lv, rv), true
+		case float64, time.Time:
+			c, ok := exprCompare(r, l)
+			return -c, ok
+		}
+	case bool:
+		if rv, ok := r.(bool); ok {
+			if lv == rv {
+				return 0, true
+			}
+			return Select(lv, 1, -1), true
+		}
+	case time.Time:
+		switch rv := r.(type) {
+		case time.Time:
+			return lv.Compare(rv), true
+		case string:
+			t := format.ParseTime(rv)
+			if t.IsZero() {
+				return 0, false
+			}
+			return lv.Compare(t), true
+		case float64:
+			c, ok := exprCompare(r, l)
+			return -c, ok
+		}
+	case time.Duration:
+		if rv, ok := r.(time.Duration); ok {
+			return compareFloat(float64(lv), float64(rv)), true
+		}
+	}
+	return 0, false
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	case a == b:
+		return 0
+	}
+	// NaN is not ordered, but must be deterministic
+	return Select(math.IsNaN(a), -1, 1)
+}
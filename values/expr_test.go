@@ -0,0 +1,175 @@
+package values
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/effective-security/x/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpr_Eval(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	format.NowFunc = func() time.Time { return now }
+	defer func() { format.NowFunc = time.Now }()
+
+	row := MapAny{
+		"status":  "active",
+		"count":   json.Number("42"),
+		"ratio":   0.5,
+		"enabled": true,
+		"created": now.Add(-72 * time.Hour).Format(time.RFC3339),
+		"updated": now.Add(-30 * 24 * time.Hour),
+		"unix":    now.Add(-time.Hour).Unix(),
+		"unix_ms": now.Add(-time.Hour).UnixMilli(),
+		"labels": map[string]any{
+			"team": "a",
+			"tier": 1,
+		},
+		"tags":  []string{"x", "y"},
+		"items": []any{1, 2, 3},
+		"name":  "it's \"quoted\"",
+	}
+
+	tcases := []struct {
+		expr string
+		exp  bool
+	}{
+		{`status == "active"`, true},
+		{`status != 'active'`, false},
+		{`status == "active" && labels.team in ["a","b"] && created > now-7d`, true},
+		{`status == "active" && labels.team in ["c"]`, false},
+		{`labels.team not in ["c", "d"]`, true},
+		{`labels.tier == 1 && labels.tier in [1, 2]`, true},
+		{`count == 42 && count > 41.5 && count <= 42`, true},
+		{`count >= 43 || ratio < 1`, true},
+		{`count == "42"`, true},
+		{`-count < 0 && -(1 - 2) == 1`, true},
+		{`enabled`, true},
+		{`!enabled`, false},
+		{`!(enabled && status == "x")`, true},
+		{`enabled == true && enabled != false`, true},
+		{`updated < now - 7d && updated > now - 5w`, true},
+		{`updated > "2024-01-01T00:00:00Z"`, true},
+		{`created < "2024-06-13" && created > "2024-06-12"`, true},
+		{`unix > now - 2h && unix < now`, true},
+		{`unix_ms > now - 2h && unix_ms < now`, true},
+		{`now - 1d + 24h == now`, true},
+		{`24h == 1d && 1440m == 1d && 1.5h > 89m`, true},
+		{`tags contains "y" && !(tags contains "z")`, true},
+		{`items contains 2`, true},
+		{`status contains "act"`, true},
+		{`missing == null && missing != 1 && !missing`, true},
+		{`missing in [1]`, false},
+		{`missing.nested contains "x"`, false},
+		{`labels.missing.deep == null`, true},
+		{`status > 1`, false},
+		{`name == "it's \"quoted\""`, true},
+		{`labels == null`, false},
+		{`items == [1, 2, 3]`, true},
+		{`1e2 == 100`, true},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := CompileExpr(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expr, e.String())
+
+			res, err := e.Eval(row)
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, res)
+			assert.Equal(t, tc.exp, e.Match(row))
+		})
+	}
+}
+
+func TestExpr_EvalErrors(t *testing.T) {
+	row := MapAny{"status": "active", "count": 1}
+
+	tcases := []struct {
+		expr string
+		err  string
+	}{
+		{`status`, "expression result is not a boolean: string"},
+		{`count`, "expression result is not a boolean: float64"},
+		{`status && true`, "expected boolean, got string"},
+		{`!status`, "position 1: cannot negate string"},
+		{`status + 1 == 2`, "position 8: invalid operation string + float64"},
+		{`now + 1 > now`, "position 5: invalid operation time.Time + float64"},
+		{`status in "active"`, `position 8: "in" requires a list, got string`},
+		{`count contains 1`, `position 7: "contains" requires a list or string, got float64`},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.expr, func(t *testing.T) {
+			e := MustCompileExpr(tc.expr)
+			_, err := e.Eval(row)
+			assert.EqualError(t, err, tc.err)
+			assert.False(t, e.Match(row))
+		})
+	}
+}
+
+func TestExpr_ParseErrors(t *testing.T) {
+	tcases := []struct {
+		expr string
+		err  string
+	}{
+		{``, `parse error at position 1: unexpected end of expression`},
+		{`status ==`, `parse error at position 10: unexpected end of expression`},
+		{`status = "a"`, `parse error at position 8: unexpected character '='`},
+		{`status == "a`, `parse error at position 11: unterminated string`},
+		{`(a == 1`, `parse error at position 8: expected ")", got end of expression`},
+		{`a in [1 2]`, `parse error at position 9: expected "," or "]", got "2"`},
+		{`a not [1]`, `parse error at position 7: expected "in" after "not", got "["`},
+		{`a == 1 b`, `parse error at position 8: unexpected "b"`},
+		{`a > now - 7x`, `parse error at position 12: invalid duration unit "x"`},
+		{`a == 1.2.3`, `parse error at position 6: invalid number "1.2.3"`},
+		{`a..b == 1`, `parse error at position 1: invalid field path "a..b"`},
+		{`in == 1`, `parse error at position 1: unexpected "in"`},
+		{`a == 1 && || b`, `parse error at position 11: unexpected "||"`},
+		{strings.Repeat("(", 100) + "a" + strings.Repeat(")", 100), `parse error at position 66: expression is nested too deeply`},
+		{strings.Repeat("!", 100) + "a", `parse error at position 66: expression is nested too deeply`},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := CompileExpr(tc.expr)
+			assert.EqualError(t, err, tc.err)
+
+			var perr *ExprError
+			assert.ErrorAs(t, err, &perr)
+		})
+	}
+
+	assert.Panics(t, func() {
+		MustCompileExpr("==")
+	})
+}
+
+func TestExpr_Filter(t *testing.T) {
+	var rows []MapAny
+	for _, js := range []string{
+		`{"id":1,"status":"active","labels":{"team":"a"}}`,
+		`{"id":2,"status":"inactive","labels":{"team":"a"}}`,
+		`{"id":3,"status":"active","labels":{"team":"c"}}`,
+		`{"id":4,"status":"active"}`,
+		`{"id":5,"status":"active","labels":{"team":"b"}}`,
+	} {
+		var m MapAny
+		require.NoError(t, m.Scan(js))
+		rows = append(rows, m)
+	}
+
+	e := MustCompileExpr(`status == "active" && labels.team in ["a", "b"]`)
+	res := e.Filter(rows)
+	require.Len(t, res, 2)
+	assert.Equal(t, 1, res[0].Int("id"))
+	assert.Equal(t, 5, res[1].Int("id"))
+
+	assert.Empty(t, MustCompileExpr(`id > 10`).Filter(rows))
+}
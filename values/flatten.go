@@ -0,0 +1,245 @@
+package values
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ArrayNotation specifies how array indexes are written in flattened keys
+type ArrayNotation int
+
+const (
+	// ArrayBrackets writes indexes in brackets: a.b[0].c
+	ArrayBrackets ArrayNotation = iota
+	// ArraySeparator writes indexes as keys: a.b.0.c,
+	// on Unflatten numeric keys always produce arrays
+	ArraySeparator
+)
+
+// FlattenOptions configures Flatten and Unflatten
+type FlattenOptions struct {
+	// Separator between nested keys, "." by default
+	Separator string
+	// Arrays specifies the notation for array indexes
+	Arrays ArrayNotation
+}
+
+func (o FlattenOptions) separator() string {
+	if o.Separator == "" {
+		return "."
+	}
+	return o.Separator
+}
+
+// Flatten returns a single level map with nested keys joined by sep,
+// and array indexes in brackets: a.b[0].c.
+// Empty maps and arrays are preserved as values.
+// An error is returned if different paths produce the same key,
+// for example {"a.b":1} and {"a":{"b":2}}.
+func (c MapAny) Flatten(sep string) (MapAny, error) {
+	return c.FlattenWith(FlattenOptions{Separator: sep})
+}
+
+// FlattenWith returns a single level map with nested keys, see Flatten
+func (c MapAny) FlattenWith(opts FlattenOptions) (MapAny, error) {
+	if c == nil {
+		return nil, nil
+	}
+	res := MapAny{}
+	src, _ := CastMapAny(cloneJSONValue(c))
+	if err := opts.flattenMap(res, "", src); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (o FlattenOptions) flattenMap(res MapAny, prefix string, m MapAny) error {
+	if len(m) == 0 && prefix != "" {
+		return setFlat(res, prefix, MapAny{})
+	}
+	for _, k := range m.OrderedKeys() {
+		key := k
+		if prefix != "" {
+			key = prefix + o.separator() + k
+		}
+		if err := o.flattenValue(res, key, m[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o FlattenOptions) flattenValue(res MapAny, key string, v any) error {
+	switch x := v.(type) {
+	case MapAny:
+		return o.flattenMap(res, key, x)
+	case []any:
+		if len(x) == 0 {
+			return setFlat(res, key, []any{})
+		}
+		for i, item := range x {
+			var ik string
+			if o.Arrays == ArraySeparator {
+				ik = key + o.separator() + strconv.Itoa(i)
+			} else {
+				ik = key + "[" + strconv.Itoa(i) + "]"
+			}
+			if err := o.flattenValue(res, ik, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return setFlat(res, key, v)
+	}
+}
+
+func setFlat(res MapAny, key string, v any) error {
+	if _, exists := res[key]; exists {
+		return errors.Errorf("flatten: key collision %q", key)
+	}
+	res[key] = v
+	return nil
+}
+
+// Unflatten reconstructs nested maps and arrays from keys produced by Flatten
+func (c MapAny) Unflatten(sep string) (MapAny, error) {
+	return c.UnflattenWith(FlattenOptions{Separator: sep})
+}
+
+// UnflattenWith reconstructs nested maps and arrays, see Unflatten.
+// An error is returned if keys conflict, for example "a" and "a.b", even if "a" is null,
+// or if the same path is used both as a map and an array.
+// Arrays can be sparse, but an index greater than the number of keys
+// is rejected, so that a single key cannot allocate a huge array.
+func (c MapAny) UnflattenWith(opts FlattenOptions) (MapAny, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	var root any = MapAny{}
+	for _, k := range c.OrderedKeys() {
+		path, err := opts.parseKey(k)
+		if err != nil {
+			return nil, err
+		}
+		for _, seg := range path {
+			if seg.index > len(c) {
+				return nil, errors.Errorf("unflatten: array index %d exceeds the number of keys in key %q", seg.index, k)
+			}
+		}
+		root, err = unflattenSet(root, path, cloneJSONValue(c[k]), k)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return root.(MapAny), nil
+}
+
+// flatSegment is a map key, or an array index when index >= 0
+type flatSegment struct {
+	key   string
+	index int
+}
+
+func (o FlattenOptions) parseKey(key string) ([]flatSegment, error) {
+	var path []flatSegment
+	for _, part := range strings.Split(key, o.separator()) {
+		if o.Arrays == ArraySeparator {
+			if idx, err := strconv.Atoi(part); err == nil && idx >= 0 && len(path) > 0 {
+				path = append(path, flatSegment{index: idx})
+				continue
+			}
+			path = append(path, flatSegment{key: part, index: -1})
+			continue
+		}
+
+		name, rest, hasIndex := strings.Cut(part, "[")
+		if name != "" || !hasIndex {
+			path = append(path, flatSegment{key: name, index: -1})
+		}
+		for hasIndex {
+			var idxText string
+			var ok bool
+			idxText, rest, ok = strings.Cut(rest, "]")
+			idx, err := strconv.Atoi(idxText)
+			if !ok || err != nil || idx < 0 || len(path) == 0 {
+				return nil, errors.Errorf("unflatten: invalid array index in key %q", key)
+			}
+			path = append(path, flatSegment{index: idx})
+			if rest == "" {
+				break
+			}
+			if rest[0] != '[' {
+				return nil, errors.Errorf("unflatten: invalid array index in key %q", key)
+			}
+			rest = rest[1:]
+		}
+	}
+	return path, nil
+}
+
+// unflattenSet returns node with value set at path
+func unflattenSet(node any, path []flatSegment, value any, key string) (any, error) {
+	if len(path) == 0 {
+		if node != nil && !isEmptyContainer(node) {
+			return nil, errors.Errorf("unflatten: key conflict %q", key)
+		}
+		if node != nil && isEmptyContainer(value) {
+			// a nested value was already set
+			return node, nil
+		}
+		return value, nil
+	}
+
+	seg := path[0]
+	if seg.index < 0 {
+		if node == nil {
+			node = MapAny{}
+		}
+		m, ok := node.(MapAny)
+		if !ok {
+			return nil, errors.Errorf("unflatten: key conflict %q", key)
+		}
+		existing, exists := m[seg.key]
+		if exists && existing == nil {
+			// an explicit null, as "a" in {"a": null, "a.b": 1}
+			return nil, errors.Errorf("unflatten: key conflict %q", key)
+		}
+		child, err := unflattenSet(existing, path[1:], value, key)
+		if err != nil {
+			return nil, err
+		}
+		m[seg.key] = child
+		return m, nil
+	}
+
+	if node == nil {
+		node = []any{}
+	}
+	list, ok := node.([]any)
+	if !ok {
+		return nil, errors.Errorf("unflatten: key conflict %q", key)
+	}
+	for len(list) <= seg.index {
+		list = append(list, nil)
+	}
+	child, err := unflattenSet(list[seg.index], path[1:], value, key)
+	if err != nil {
+		return nil, err
+	}
+	list[seg.index] = child
+	return list, nil
+}
+
+func isEmptyContainer(v any) bool {
+	switch x := v.(type) {
+	case MapAny:
+		return len(x) == 0
+	case []any:
+		return len(x) == 0
+	}
+	return false
+}
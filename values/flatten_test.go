@@ -0,0 +1,187 @@
+package values
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatten(t *testing.T) {
+	m := MapAny{
+		"a": MapAny{
+			"b": []any{
+				map[string]any{"c": 1},
+				[]string{"x", "y"},
+			},
+			"empty":  MapAny{},
+			"list":   []int{},
+			"nil":    nil,
+			"string": "s",
+		},
+		"top": true,
+	}
+
+	flat, err := m.Flatten(".")
+	require.NoError(t, err)
+	assert.Equal(t, MapAny{
+		"a.b[0].c":  1,
+		"a.b[1][0]": "x",
+		"a.b[1][1]": "y",
+		"a.empty":   MapAny{},
+		"a.list":    []any{},
+		"a.nil":     nil,
+		"a.string":  "s",
+		"top":       true,
+	}, flat)
+	assert.Equal(t, []string{"a.b[0].c", "a.b[1][0]", "a.b[1][1]", "a.empty", "a.list", "a.nil", "a.string", "top"}, flat.OrderedKeys())
+
+	back, err := flat.Unflatten(".")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":{"b":[{"c":1},["x","y"]],"empty":{},"list":[],"nil":null,"string":"s"},"top":true}`, back.JSON())
+
+	// environment variables style
+	opts := FlattenOptions{Separator: "__", Arrays: ArraySeparator}
+	flat, err = m.FlattenWith(opts)
+	require.NoError(t, err)
+	env := flat.RenameKeys(strings.ToUpper)
+	assert.Equal(t, []string{"A__B__0__C", "A__B__1__0", "A__B__1__1", "A__EMPTY", "A__LIST", "A__NIL", "A__STRING", "TOP"}, env.OrderedKeys())
+
+	// RenameKeys returns empty lists as nil
+	back, err = env.RenameKeys(strings.ToLower).UnflattenWith(opts)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":{"b":[{"c":1},["x","y"]],"empty":{},"list":null,"nil":null,"string":"s"},"top":true}`, back.JSON())
+
+	var nilMap MapAny
+	flat, err = nilMap.Flatten(".")
+	require.NoError(t, err)
+	assert.Nil(t, flat)
+	back, err = nilMap.Unflatten(".")
+	require.NoError(t, err)
+	assert.Nil(t, back)
+
+	// default separator
+	flat, err = MapAny{"a": MapAny{"b": 1}}.FlattenWith(FlattenOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, MapAny{"a.b": 1}, flat)
+}
+
+func TestFlatten_Collision(t *testing.T) {
+	_, err := MapAny{"a.b": 1, "a": MapAny{"b": 2}}.Flatten(".")
+	assert.EqualError(t, err, `flatten: key collision "a.b"`)
+
+	_, err = MapAny{"a[0]": 1, "a": []any{2}}.Flatten(".")
+	assert.EqualError(t, err, `flatten: key collision "a[0]"`)
+
+	_, err = MapAny{"a.0": 1, "a": []any{2}}.FlattenWith(FlattenOptions{Arrays: ArraySeparator})
+	assert.EqualError(t, err, `flatten: key collision "a.0"`)
+}
+
+func TestUnflatten(t *testing.T) {
+	tcases := []struct {
+		name string
+		m    MapAny
+		opts FlattenOptions
+		exp  string
+		err  string
+	}{
+		{
+			name: "sparse array",
+			m:    MapAny{"a[2]": 1, "a[0].b": 2},
+			exp:  `{"a":[{"b":2},null,1]}`,
+		},
+		{
+			name: "nested arrays",
+			m:    MapAny{"a[0][1]": "x", "b": "y"},
+			exp:  `{"a":[[null,"x"]],"b":"y"}`,
+		},
+		{
+			name: "numeric keys without array notation",
+			m:    MapAny{"a.0": 1},
+			exp:  `{"a":{"0":1}}`,
+		},
+		{
+			name: "numeric keys with separator notation",
+			m:    MapAny{"a/1": 1, "0": 2},
+			opts: FlattenOptions{Separator: "/", Arrays: ArraySeparator},
+			exp:  `{"0":2,"a":[null,1]}`,
+		},
+		{
+			name: "scalar conflict",
+			m:    MapAny{"a": 1, "a.b": 2},
+			err:  `unflatten: key conflict "a.b"`,
+		},
+		{
+			name: "array conflict",
+			m:    MapAny{"a.b": 1, "a[0]": 2},
+			err:  `unflatten: key conflict "a[0]"`,
+		},
+		{
+			name: "null conflict",
+			m:    MapAny{"a": nil, "a.b": 1},
+			err:  `unflatten: key conflict "a.b"`,
+		},
+		{
+			name: "nested null conflict",
+			m:    MapAny{"a[0].b": nil, "a[0].b.c": 1},
+			err:  `unflatten: key conflict "a[0].b.c"`,
+		},
+		{
+			name: "map conflict",
+			m:    MapAny{"a.b": 1, "a": 2},
+			err:  `unflatten: key conflict "a.b"`,
+		},
+		{
+			name: "empty map",
+			m:    MapAny{"a": MapAny{}, "a.b": 2},
+			exp:  `{"a":{"b":2}}`,
+		},
+		{
+			name: "invalid index",
+			m:    MapAny{"a[x]": 1},
+			err:  `unflatten: invalid array index in key "a[x]"`,
+		},
+		{
+			name: "unterminated index",
+			m:    MapAny{"a[1": 1},
+			err:  `unflatten: invalid array index in key "a[1"`,
+		},
+		{
+			name: "garbage after index",
+			m:    MapAny{"a[1]x": 1},
+			err:  `unflatten: invalid array index in key "a[1]x"`,
+		},
+		{
+			name: "index too large",
+			m:    MapAny{"a[1000000000]": 1},
+			err:  `unflatten: array index 1000000000 exceeds the number of keys in key "a[1000000000]"`,
+		},
+		{
+			name: "separator index too large",
+			m:    MapAny{"a/3": 1, "b": 2},
+			opts: FlattenOptions{Separator: "/", Arrays: ArraySeparator},
+			err:  `unflatten: array index 3 exceeds the number of keys in key "a/3"`,
+		},
+		{
+			name: "root index",
+			m:    MapAny{"[1]": 1},
+			err:  `unflatten: invalid array index in key "[1]"`,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.opts.Separator == "" {
+				tc.opts.Separator = "."
+			}
+			res, err := tc.m.UnflattenWith(tc.opts)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, res.JSON())
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package values
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/zeebo/xxh3"
+)
+
+// StructHasher computes stable structural XXH3 digests of arbitrary Go values,
+// suitable for cache keys and change detection fingerprints.
+//
+// The digest depends on the data, not on the memory layout:
+// - signed integers, unsigned integers and floats are hashed by value, regardless of their size;
+// - pointers and interfaces are hashed by the value they refer to;
+// - maps are order-independent;
+// - structs are hashed by exported field names and values,
+// and embedded structs of unexported types by their exported fields,
+// fields tagged with `hash:"-"` are skipped;
+// - structs with only unexported fields, such as big.Int or netip.Addr,
+// are hashed by the output of encoding.BinaryMarshaler, encoding.TextMarshaler
+// or json.Marshaler, and are not supported if they implement none of them;
+// - time.Time is hashed by the instant, regardless of the location.
+//
+// Channels, functions and unsafe pointers are not supported.
+type StructHasher struct {
+	// DetectCycles enables detection of reference cycles via pointers, maps and slices,
+	// without it a cyclic value causes infinite recursion.
+	DetectCycles bool
+}
+
+// XXH3HashValue64 returns a 64-bit structural XXH3 digest of v, see StructHasher
+func XXH3HashValue64(v any) (uint64, error) {
+	return StructHasher{}.Hash64(v)
+}
+
+// XXH3HashValue128 returns a 128-bit structural XXH3 digest of v, see StructHasher
+func XXH3HashValue128(v any) ([]byte, error) {
+	return StructHasher{}.Hash128(v)
+}
+
+// Hash64 returns a 64-bit structural XXH3 digest of v
+func (s StructHasher) Hash64(v any) (uint64, error) {
+	hash := xxh3.New()
+	if err := s.newWriter(hash).write(reflect.ValueOf(v)); err != nil {
+		return 0, err
+	}
+	return hash.Sum64(), nil
+}
+
+// Hash128 returns a 128-bit structural XXH3 digest of v
+func (s StructHasher) Hash128(v any) ([]byte, error) {
+	hash := xxh3.New128()
+	if err := s.newWriter(hash).write(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// value kinds, written before each value so different shapes do not collide
+const (
+	hashKindNil byte = iota + 1
+	hashKindBool
+	hashKindInt
+	hashKindUint
+	hashKindFloat
+	hashKindComplex
+	hashKindString
+	hashKindBytes
+	hashKindList
+	hashKindMap
+	hashKindStruct
+	hashKindTime
+	hashKindMarshaled
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+type structHashWriter struct {
+	h        hasher
+	visiting map[hashVisit]bool
+}
+
+type hashVisit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func (s StructHasher) newWriter(h hasher) *structHashWriter {
+	w := &structHashWriter{h: h}
+	if s.DetectCycles {
+		w.visiting = map[hashVisit]bool{}
+	}
+	return w
+}
+
+func (w *structHashWriter) kind(k byte) {
+	hashWrite(w.h, []byte{k})
+}
+
+func (w *structHashWriter) string(s string) {
+	hashWriteUint64(w.h, uint64(len(s)))
+	hashWriteString(w.h, s)
+}
+
+// enter marks the reference as being visited, and returns false on a cycle
+func (w *structHashWriter) enter(v reflect.Value) bool {
+	if w.visiting == nil {
+		return true
+	}
+	key := hashVisit{ptr: v.Pointer(), typ: v.Type()}
+	if w.visiting[key] {
+		return false
+	}
+	w.visiting[key] = true
+	return true
+}
+
+func (w *structHashWriter) leave(v reflect.Value) {
+	if w.visiting != nil {
+		delete(w.visiting, hashVisit{ptr: v.Pointer(), typ: v.Type()})
+	}
+}
+
+func (w *structHashWriter) write(v reflect.Value) error {
+	if !v.IsValid() {
+		w.kind(hashKindNil)
+		return nil
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		w.kind(hashKindTime)
+		hashWriteUint64(w.h, uint64(t.Unix()))
+		hashWriteUint32(w.h, uint32(t.Nanosecond()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		w.kind(hashKindBool)
+		hashWrite(w.h, BoolToBytes(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.kind(hashKindInt)
+		hashWriteUint64(w.h, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.kind(hashKindUint)
+		hashWriteUint64(w.h, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		w.kind(hashKindFloat)
+		hashWriteUint64(w.h, hashFloatBits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		w.kind(hashKindComplex)
+		hashWriteUint64(w.h, hashFloatBits(real(c)))
+		hashWriteUint64(w.h, hashFloatBits(imag(c)))
+	case reflect.String:
+		w.kind(hashKindString)
+		w.string(v.String())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			w.kind(hashKindNil)
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			return w.write(v.Elem())
+		}
+		if !w.enter(v) {
+			return errors.Errorf("cycle detected: %s", v.Type())
+		}
+		defer w.leave(v)
+		return w.write(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			w.kind(hashKindNil)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			w.kind(hashKindBytes)
+			hashWriteUint64(w.h, uint64(v.Len()))
+			hashWrite(w.h, v.Bytes())
+			return nil
+		}
+		if !w.enter(v) {
+			return errors.Errorf("cycle detected: %s", v.Type())
+		}
+		defer w.leave(v)
+		return w.writeList(v)
+	case reflect.Array:
+		return w.writeList(v)
+	case reflect.Map:
+		if v.IsNil() {
+			w.kind(hashKindNil)
+			return nil
+		}
+		if !w.enter(v) {
+			return errors.Errorf("cycle detected: %s", v.Type())
+		}
+		defer w.leave(v)
+		return w.writeMap(v)
+	case reflect.Struct:
+		return w.writeStruct(v)
+	default:
+		return errors.Errorf("unsupported type: %s", v.Type())
+	}
+	return nil
+}
+
+func (w *structHashWriter) writeList(v reflect.Value) error {
+	w.kind(hashKindList)
+	hashWriteUint64(w.h, uint64(v.Len()))
+	for i := 0; i < v.Len(); i++ {
+		if err := w.write(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMap hashes each entry separately and writes sorted entry digests,
+// so the result does not depend on the iteration order
+func (w *structHashWriter) writeMap(v reflect.Value) error {
+	digests := make([][]byte, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entry := xxh3.New128()
+		ew := &structHashWriter{h: entry, visiting: w.visiting}
+		if err := ew.write(iter.Key()); err != nil {
+			return err
+		}
+		if err := ew.write(iter.Value()); err != nil {
+			return err
+		}
+		digests = append(digests, entry.Sum(nil))
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		return bytes.Compare(digests[i], digests[j]) < 0
+	})
+
+	w.kind(hashKindMap)
+	hashWriteUint64(w.h, uint64(len(digests)))
+	for _, d := range digests {
+		hashWrite(w.h, d)
+	}
+	return nil
+}
+
+func (w *structHashWriter) writeStruct(v reflect.Value) error {
+	typ := v.Type()
+	if isOpaqueStruct(typ) {
+		return w.writeMarshaled(v)
+	}
+	w.kind(hashKindStruct)
+	for i := 0; i < v.NumField(); i++ {
+		field := typ.Field(i)
+		if !isHashedField(field) || field.Tag.Get("hash") == "-" {
+			continue
+		}
+		w.string(field.Name)
+		if err := w.write(v.Field(i)); err != nil {
+			return err
+		}
+	}
+	// terminate the field list, so nested structs do not merge
+	hashWrite(w.h, hashDelimiter)
+	return nil
+}
+
+// isHashedField returns true for exported fields, and for embedded structs
+// regardless of their type being exported, as their exported fields are promoted
+func isHashedField(field reflect.StructField) bool {
+	if field.IsExported() {
+		return true
+	}
+	if !field.Anonymous {
+		return false
+	}
+	typ := field.Type
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct
+}
+
+// isOpaqueStruct returns true if the struct has unexported fields only
+func isOpaqueStruct(typ reflect.Type) bool {
+	if typ.NumField() == 0 {
+		return false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if isHashedField(typ.Field(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeMarshaled hashes the struct by its marshaled form,
+// methods with pointer receivers are used as well
+func (w *structHashWriter) writeMarshaled(v reflect.Value) error {
+	if !v.CanInterface() {
+		// an embedded struct of unexported type
+		return errors.Errorf("unsupported embedded type without exported fields: %s", v.Type())
+	}
+	if !v.CanAddr() {
+		c := reflect.New(v.Type())
+		c.Elem().Set(v)
+		v = c.Elem()
+	}
+
+	var data []byte
+	var err error
+	switch m := v.Addr().Interface().(type) {
+	case encoding.BinaryMarshaler:
+		data, err = m.MarshalBinary()
+	case encoding.TextMarshaler:
+		data, err = m.MarshalText()
+	case json.Marshaler:
+		data, err = m.MarshalJSON()
+	default:
+		return errors.Errorf("unsupported type without exported fields: %s", v.Type())
+	}
+	if err != nil {
+		return errors.Wrapf(err, "unable to marshal %s", v.Type())
+	}
+
+	w.kind(hashKindMarshaled)
+	w.string(v.Type().String())
+	hashWriteUint64(w.h, uint64(len(data)))
+	hashWrite(w.h, data)
+	return nil
+}
+
+// hashFloatBits returns the bits of f, with -0 and NaN normalized
+func hashFloatBits(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+	if math.IsNaN(f) {
+		return math.Float64bits(math.NaN())
+	}
+	return math.Float64bits(f)
+}
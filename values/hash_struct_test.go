@@ -0,0 +1,259 @@
+package values
+
+import (
+	"math"
+	"math/big"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hashConfig struct {
+	Name     string
+	Replicas int
+	Labels   map[string]string
+	Ports    []uint16
+	Owner    *hashOwner
+	Created  time.Time
+	Cache    string `hash:"-"`
+	internal string
+}
+
+type hashOwner struct {
+	Email string
+	Tags  []any
+}
+
+type hashNode struct {
+	Name string
+	Next *hashNode
+}
+
+func TestXXH3HashValue(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	cfg := func() *hashConfig {
+		return &hashConfig{
+			Name:     "svc",
+			Replicas: 3,
+			Labels:   map[string]string{"a": "1", "b": "2", "c": "3"},
+			Ports:    []uint16{80, 443},
+			Owner:    &hashOwner{Email: "a@b.c", Tags: []any{"x", 1, nil}},
+			Created:  created,
+			Cache:    "ignored",
+			internal: "ignored",
+		}
+	}
+
+	h1, err := XXH3HashValue64(cfg())
+	require.NoError(t, err)
+
+	// stable across calls and map iteration order
+	for range 10 {
+		h, err := XXH3HashValue64(cfg())
+		require.NoError(t, err)
+		assert.Equal(t, h1, h)
+	}
+
+	// pointer and value hash equally
+	h, err := XXH3HashValue64(*cfg())
+	require.NoError(t, err)
+	assert.Equal(t, h1, h)
+
+	// skipped and unexported fields do not affect the digest
+	c := cfg()
+	c.Cache = "other"
+	c.internal = "other"
+	h, err = XXH3HashValue64(c)
+	require.NoError(t, err)
+	assert.Equal(t, h1, h)
+
+	// same instant in a different location
+	c = cfg()
+	c.Created = created.In(time.FixedZone("X", 3600))
+	h, err = XXH3HashValue64(c)
+	require.NoError(t, err)
+	assert.Equal(t, h1, h)
+
+	changes := []func(c *hashConfig){
+		func(c *hashConfig) { c.Name = "svc2" },
+		func(c *hashConfig) { c.Replicas = 0 },
+		func(c *hashConfig) { c.Labels["c"] = "4" },
+		func(c *hashConfig) { c.Labels = nil },
+		func(c *hashConfig) { c.Ports = []uint16{443, 80} },
+		func(c *hashConfig) { c.Owner.Tags[2] = 0 },
+		func(c *hashConfig) { c.Owner = nil },
+		func(c *hashConfig) { c.Created = created.Add(time.Nanosecond) },
+	}
+	seen := map[uint64]bool{h1: true}
+	for i, change := range changes {
+		c := cfg()
+		change(c)
+		h, err := XXH3HashValue64(c)
+		require.NoError(t, err)
+		assert.False(t, seen[h], "change %d", i)
+		seen[h] = true
+	}
+
+	b1, err := XXH3HashValue128(cfg())
+	require.NoError(t, err)
+	assert.Len(t, b1, 16)
+	b2, err := XXH3HashValue128(cfg())
+	require.NoError(t, err)
+	assert.Equal(t, b1, b2)
+}
+
+func TestXXH3HashValue_Scalars(t *testing.T) {
+	t.Parallel()
+
+	hash := func(v any) uint64 {
+		h, err := XXH3HashValue64(v)
+		require.NoError(t, err)
+		return h
+	}
+
+	// numbers are hashed by value
+	assert.Equal(t, hash(int8(1)), hash(int64(1)))
+	assert.Equal(t, hash(uint8(1)), hash(uint64(1)))
+	assert.Equal(t, hash(float32(1.5)), hash(1.5))
+	assert.Equal(t, hash(0.0), hash(math.Copysign(0, -1)))
+	assert.Equal(t, hash(math.NaN()), hash(math.NaN()))
+
+	// but different kinds do not collide
+	assert.NotEqual(t, hash(1), hash(uint(1)))
+	assert.NotEqual(t, hash(1), hash(1.0))
+	assert.NotEqual(t, hash(""), hash(nil))
+	assert.NotEqual(t, hash([]byte("ab")), hash("ab"))
+	assert.NotEqual(t, hash([]string{"ab", "c"}), hash([]string{"a", "bc"}))
+	assert.NotEqual(t, hash([]int{}), hash([]int(nil)))
+	assert.NotEqual(t, hash(map[string]int{"a": 1}), hash(map[string]int{"a": 2}))
+	assert.NotEqual(t, hash(map[string]int{"a": 1, "b": 2}), hash(map[string]int{"a": 2, "b": 1}))
+	assert.Equal(t, hash([2]int{1, 2}), hash([]int{1, 2}))
+	assert.Equal(t, hash(complex(1, 2)), hash(complex64(complex(1, 2))))
+	assert.Equal(t, hash(MapAny{"a": 1}), hash(map[string]any{"a": 1}))
+}
+
+func TestXXH3HashValue_Cycles(t *testing.T) {
+	t.Parallel()
+
+	n := &hashNode{Name: "a", Next: &hashNode{Name: "b"}}
+	n.Next.Next = n
+
+	_, err := StructHasher{DetectCycles: true}.Hash64(n)
+	assert.EqualError(t, err, "cycle detected: *values.hashNode")
+
+	m := map[string]any{}
+	m["self"] = m
+	_, err = StructHasher{DetectCycles: true}.Hash128(m)
+	assert.EqualError(t, err, "cycle detected: map[string]interface {}")
+
+	s := []any{nil}
+	s[0] = s
+	_, err = StructHasher{DetectCycles: true}.Hash64(s)
+	assert.EqualError(t, err, "cycle detected: []interface {}")
+
+	// shared references are not cycles
+	shared := &hashOwner{Email: "x"}
+	h, err := StructHasher{DetectCycles: true}.Hash64([]*hashOwner{shared, shared})
+	require.NoError(t, err)
+	h2, err := XXH3HashValue64([]*hashOwner{{Email: "x"}, {Email: "x"}})
+	require.NoError(t, err)
+	assert.Equal(t, h2, h)
+}
+
+func TestXXH3HashValue_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := XXH3HashValue64(map[string]any{"ch": make(chan int)})
+	assert.EqualError(t, err, "unsupported type: chan int")
+
+	_, err = XXH3HashValue128(struct{ F func() }{F: func() {}})
+	assert.EqualError(t, err, "unsupported type: func()")
+}
+
+type hashOpaqueConfig struct {
+	Limit *big.Int
+	Addr  netip.Addr
+	Max   big.Int
+}
+
+type hashOpaque struct {
+	value int
+}
+
+func TestXXH3HashValue_OpaqueStructs(t *testing.T) {
+	t.Parallel()
+
+	hash := func(v any) uint64 {
+		h, err := XXH3HashValue64(v)
+		require.NoError(t, err)
+		return h
+	}
+
+	c1 := hashOpaqueConfig{Limit: big.NewInt(1), Addr: netip.MustParseAddr("10.0.0.1"), Max: *big.NewInt(5)}
+	c2 := hashOpaqueConfig{Limit: big.NewInt(2), Addr: netip.MustParseAddr("10.0.0.1"), Max: *big.NewInt(5)}
+	c3 := hashOpaqueConfig{Limit: big.NewInt(1), Addr: netip.MustParseAddr("10.0.0.2"), Max: *big.NewInt(5)}
+	c4 := hashOpaqueConfig{Limit: big.NewInt(1), Addr: netip.MustParseAddr("10.0.0.1"), Max: *big.NewInt(6)}
+	assert.NotEqual(t, hash(c1), hash(c2))
+	assert.NotEqual(t, hash(c1), hash(c3))
+	assert.NotEqual(t, hash(c1), hash(c4))
+
+	same := hashOpaqueConfig{Limit: big.NewInt(1), Addr: netip.MustParseAddr("10.0.0.1"), Max: *big.NewInt(5)}
+	assert.Equal(t, hash(c1), hash(same))
+	// non-addressable value in interface
+	assert.Equal(t, hash(*big.NewInt(7)), hash(big.NewInt(7)))
+
+	_, err := XXH3HashValue64(struct{ V hashOpaque }{V: hashOpaque{value: 1}})
+	assert.EqualError(t, err, "unsupported type without exported fields: values.hashOpaque")
+
+	// empty structs are not opaque
+	assert.Equal(t, hash(struct{}{}), hash(struct{}{}))
+}
+
+type hashBase struct {
+	ID      string
+	Created time.Time
+	secret  string
+}
+
+type hashEmbedded struct {
+	hashBase
+	*hashOwner
+	Name string
+}
+
+func TestXXH3HashValue_EmbeddedUnexported(t *testing.T) {
+	t.Parallel()
+
+	hash := func(v any) uint64 {
+		h, err := XXH3HashValue64(v)
+		require.NoError(t, err)
+		return h
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	e1 := hashEmbedded{hashBase: hashBase{ID: "1", Created: created}, Name: "x"}
+	e2 := hashEmbedded{hashBase: hashBase{ID: "2", Created: created}, Name: "x"}
+	e3 := hashEmbedded{hashBase: hashBase{ID: "1", Created: created.Add(time.Second)}, Name: "x"}
+	assert.NotEqual(t, hash(e1), hash(e2))
+	assert.NotEqual(t, hash(e1), hash(e3))
+	assert.Equal(t, hash(e1), hash(&e1))
+
+	// unexported fields of the embedded struct are still skipped
+	e1.secret = "s"
+	assert.Equal(t, hash(e1), hash(hashEmbedded{hashBase: hashBase{ID: "1", Created: created}, Name: "x"}))
+
+	// embedded pointers
+	o1 := hashEmbedded{hashOwner: &hashOwner{Email: "a"}}
+	o2 := hashEmbedded{hashOwner: &hashOwner{Email: "b"}}
+	assert.NotEqual(t, hash(o1), hash(o2))
+	assert.NotEqual(t, hash(o1), hash(hashEmbedded{}))
+
+	// an embedded struct with no exported fields is opaque
+	_, err := XXH3HashValue64(struct{ hashOpaque }{hashOpaque{value: 1}})
+	assert.EqualError(t, err, "unsupported embedded type without exported fields: values.hashOpaque")
+}
@@ -0,0 +1,260 @@
+package values
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/errors"
+)
+
+// CanonicalForm specifies the canonical JSON serialization
+type CanonicalForm int
+
+const (
+	// CanonicalDefault sorts object keys and preserves json.Number as provided,
+	// see WriteCanonicalJSON
+	CanonicalDefault CanonicalForm = iota
+	// CanonicalJCS produces RFC 8785 JSON Canonicalization Scheme output,
+	// see WriteJCS
+	CanonicalJCS
+)
+
+// MarshalJCS returns RFC 8785 (JCS) canonical JSON representation of v
+func MarshalJCS(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteJCS(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CanonicalizeJCS returns RFC 8785 (JCS) canonical JSON representation of the input
+func CanonicalizeJCS(input []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(input))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "decode json")
+	}
+	return MarshalJCS(v)
+}
+
+// WriteJCS writes RFC 8785 (JCS) canonical JSON representation of v:
+// - object keys are sorted by their UTF-16 code units;
+// - numbers are serialized as IEEE 754 doubles, using ECMAScript rules;
+// - strings are escaped minimally, without HTML escaping.
+// Values other than JSON primitives, maps and []any are converted with json.Marshal first.
+func WriteJCS(buf *bytes.Buffer, v any) error {
+	return writeJCS(buf, v)
+}
+
+func writeJCS(buf jsonWriter, v any) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+		return nil
+	case bool:
+		buf.WriteString(Select(x, "true", "false"))
+		return nil
+	case string:
+		return jcsWriteString(buf, x)
+	case json.Number:
+		f, err := x.Float64()
+		if err != nil {
+			return errors.Wrapf(err, "invalid number: %s", x)
+		}
+		return jcsWriteNumber(buf, f)
+	case float64:
+		return jcsWriteNumber(buf, x)
+	case float32:
+		return jcsWriteNumber(buf, float64(x))
+	case int:
+		return jcsWriteNumber(buf, float64(x))
+	case int8:
+		return jcsWriteNumber(buf, float64(x))
+	case int16:
+		return jcsWriteNumber(buf, float64(x))
+	case int32:
+		return jcsWriteNumber(buf, float64(x))
+	case int64:
+		return jcsWriteNumber(buf, float64(x))
+	case uint:
+		return jcsWriteNumber(buf, float64(x))
+	case uint8:
+		return jcsWriteNumber(buf, float64(x))
+	case uint16:
+		return jcsWriteNumber(buf, float64(x))
+	case uint32:
+		return jcsWriteNumber(buf, float64(x))
+	case uint64:
+		return jcsWriteNumber(buf, float64(x))
+	case map[string]any:
+		return jcsWriteObject(buf, x)
+	case MapAny:
+		return jcsWriteObject(buf, x)
+	case []any:
+		buf.WriteByte('[')
+		for i := range x {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJCS(buf, x[i]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	default:
+		raw, err := json.Marshal(x)
+		if err != nil {
+			return errors.Wrap(err, "marshal fallback")
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+
+		var val any
+		if err := dec.Decode(&val); err != nil {
+			return errors.Wrap(err, "decode fallback")
+		}
+		return writeJCS(buf, val)
+	}
+}
+
+func jcsWriteObject(buf jsonWriter, m map[string]any) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return compareUTF16(keys[i], keys[j]) < 0
+	})
+
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := jcsWriteString(buf, k); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+		if err := writeJCS(buf, m[k]); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// compareUTF16 compares strings by their UTF-16 code units, as required by RFC 8785
+func compareUTF16(a, b string) int {
+	return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+}
+
+const hexDigits = "0123456789abcdef"
+
+func jcsWriteString(buf jsonWriter, s string) error {
+	if !utf8.ValidString(s) {
+		return errors.Errorf("invalid UTF-8 string: %q", s)
+	}
+
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return nil
+}
+
+func jcsWriteNumber(buf jsonWriter, f float64) error {
+	s, err := FormatJCSNumber(f)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(s)
+	return nil
+}
+
+// FormatJCSNumber returns the ECMAScript Number.prototype.toString representation of f,
+// as required by RFC 8785. NaN and Infinity are not supported.
+func FormatJCSNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", errors.Errorf("unsupported number: %v", f)
+	}
+	if f == 0 {
+		// covers -0 as well
+		return "0", nil
+	}
+
+	var b strings.Builder
+	if f < 0 {
+		b.WriteByte('-')
+		f = -f
+	}
+
+	// shortest representation that round-trips, in the d.ddde±xx form
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+
+	// n is the position of the decimal point relative to the digits
+	n := e + 1
+	k := len(digits)
+	switch {
+	case k <= n && n <= 21:
+		b.WriteString(digits)
+		b.WriteString(strings.Repeat("0", n-k))
+	case 0 < n && n <= 21:
+		b.WriteString(digits[:n])
+		b.WriteByte('.')
+		b.WriteString(digits[n:])
+	case -6 < n && n <= 0:
+		b.WriteString("0.")
+		b.WriteString(strings.Repeat("0", -n))
+		b.WriteString(digits)
+	default:
+		b.WriteByte(digits[0])
+		if k > 1 {
+			b.WriteByte('.')
+			b.WriteString(digits[1:])
+		}
+		b.WriteByte('e')
+		if n-1 >= 0 {
+			b.WriteByte('+')
+		}
+		b.WriteString(strconv.Itoa(n - 1))
+	}
+	return b.String(), nil
+}
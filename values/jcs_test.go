@@ -0,0 +1,156 @@
+package values
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc8785 Appendix B
+func Test_FormatJCSNumber(t *testing.T) {
+	tcases := []struct {
+		bits uint64
+		exp  string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.exp, func(t *testing.T) {
+			s, err := FormatJCSNumber(math.Float64frombits(tc.bits))
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, s)
+		})
+	}
+
+	_, err := FormatJCSNumber(math.NaN())
+	assert.EqualError(t, err, "unsupported number: NaN")
+	_, err = FormatJCSNumber(math.Inf(1))
+	assert.EqualError(t, err, "unsupported number: +Inf")
+}
+
+func Test_CanonicalizeJCS(t *testing.T) {
+	tcases := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{
+			// rfc8785 Section 3.2.2
+			name: "sample",
+			input: `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			exp: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// rfc8785 Section 3.2.3
+			name: "sorting",
+			input: `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+			exp: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\",\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{
+			name:  "no html escaping",
+			input: `{"a":"<b>&</b>","b":"\u2028"}`,
+			exp:   "{\"a\":\"<b>&</b>\",\"b\":\"\u2028\"}",
+		},
+		{
+			name:  "integers",
+			input: `[0, -0, 1.0, 100, 1e2, 12345678901234567890]`,
+			exp:   `[0,0,1,100,100,12345678901234567000]`,
+		},
+		{
+			name:  "invalid",
+			input: `{"a":`,
+			err:   "decode json: unexpected EOF",
+		},
+		{
+			name:  "out of range",
+			input: `[1e400]`,
+			err:   `invalid number: 1e400: strconv.ParseFloat: parsing "1e400": value out of range`,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CanonicalizeJCS([]byte(tc.input))
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, string(got))
+		})
+	}
+}
+
+func Test_MarshalJCS(t *testing.T) {
+	type obj struct {
+		Name  string  `json:"name"`
+		Value float32 `json:"value"`
+	}
+
+	m := MapAny{
+		"b":      []int{2, 1, 3},
+		"a":      []string{"2", "1", "3"},
+		"obj":    obj{Name: "<x>", Value: 1.5},
+		"map":    map[string]int{"z": 1, "y": 2},
+		"nested": map[string]any{"n": json.Number("1.50"), "i8": int8(-1), "u16": uint16(2)},
+		"f32":    float32(0.1),
+		"null":   nil,
+	}
+	exp := `{"a":["2","1","3"],"b":[2,1,3],"f32":0.10000000149011612,"map":{"y":2,"z":1},"nested":{"i8":-1,"n":1.5,"u16":2},"null":null,"obj":{"name":"<x>","value":1.5}}`
+
+	got, err := MarshalJCS(m)
+	require.NoError(t, err)
+	assert.Equal(t, exp, string(got))
+
+	got, err = m.CanonicalJSON(CanonicalJCS)
+	require.NoError(t, err)
+	assert.Equal(t, exp, string(got))
+
+	_, err = MarshalJCS(MapAny{"nan": math.NaN()})
+	assert.EqualError(t, err, "unsupported number: NaN")
+
+	_, err = MarshalJCS(string([]byte{0xff}))
+	assert.EqualError(t, err, `invalid UTF-8 string: "\xff"`)
+
+	_, err = MarshalJCS(MapAny{"ch": make(chan int)})
+	assert.EqualError(t, err, "marshal fallback: json: unsupported type: chan int")
+}
@@ -0,0 +1,105 @@
+package values
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+)
+
+// JSONColumn is a nullable database column of any type, stored as JSON,
+// for example in PostgreSQL JSONB column.
+// It implements sql.Scanner, driver.Valuer, json.Marshaler and json.Unmarshaler.
+type JSONColumn[T any] struct {
+	V T
+	// Valid is false for NULL
+	Valid bool
+}
+
+// NewJSONColumn returns a valid column with the value
+func NewJSONColumn[T any](v T) JSONColumn[T] {
+	return JSONColumn[T]{V: v, Valid: true}
+}
+
+// Scan implements the Scanner interface.
+func (c *JSONColumn[T]) Scan(value any) error {
+	var zero T
+	c.V, c.Valid = zero, false
+
+	var s []byte
+	switch vid := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		s = vid
+	case string:
+		s = []byte(vid)
+	default:
+		return errors.Errorf("unsupported scan type: %T", value)
+	}
+
+	if len(s) == 0 || bytes.Equal(s, nullJSON) {
+		return nil
+	}
+	if err := json.Unmarshal(s, &c.V); err != nil {
+		return errors.WithStack(err)
+	}
+	c.Valid = true
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (c JSONColumn[T]) Value() (driver.Value, error) {
+	if !c.Valid {
+		return nil, nil
+	}
+	value, err := json.Marshal(c.V)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return string(value), nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (c JSONColumn[T]) MarshalJSON() ([]byte, error) {
+	if !c.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(c.V)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *JSONColumn[T]) UnmarshalJSON(data []byte) error {
+	return c.Scan(data)
+}
+
+// CanonicalJSONColumn is JSONColumn that stores canonical JSON,
+// see CanonicalizeJSON, so equal values produce equal column values
+// and can be compared or indexed in the database.
+type CanonicalJSONColumn[T any] struct {
+	JSONColumn[T]
+}
+
+// NewCanonicalJSONColumn returns a valid column with the value
+func NewCanonicalJSONColumn[T any](v T) CanonicalJSONColumn[T] {
+	return CanonicalJSONColumn[T]{JSONColumn: NewJSONColumn(v)}
+}
+
+// Value implements the driver Valuer interface.
+func (c CanonicalJSONColumn[T]) Value() (driver.Value, error) {
+	if !c.Valid {
+		return nil, nil
+	}
+	value, err := json.Marshal(c.V)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	value, err = CanonicalizeJSON(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(value), nil
+}
+
+var nullJSON = []byte("null")
@@ -0,0 +1,118 @@
+package values
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonColumnItem struct {
+	Name  string         `json:"name"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+var (
+	_ sql.Scanner   = (*JSONColumn[int])(nil)
+	_ driver.Valuer = JSONColumn[int]{}
+	_ sql.Scanner   = (*CanonicalJSONColumn[int])(nil)
+	_ driver.Valuer = CanonicalJSONColumn[int]{}
+)
+
+func TestJSONColumn(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		c := NewJSONColumn(jsonColumnItem{Name: "a", Attrs: map[string]any{"b": 1}})
+		v, err := c.Value()
+		require.NoError(t, err)
+		assert.Equal(t, `{"name":"a","attrs":{"b":1}}`, v)
+
+		var c2 JSONColumn[jsonColumnItem]
+		require.NoError(t, c2.Scan([]byte(v.(string))))
+		assert.True(t, c2.Valid)
+		assert.Equal(t, "a", c2.V.Name)
+		assert.Equal(t, float64(1), c2.V.Attrs["b"])
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		var c JSONColumn[[]string]
+		require.NoError(t, c.Scan(`["a","b"]`))
+		assert.Equal(t, NewJSONColumn([]string{"a", "b"}), c)
+
+		v, err := c.Value()
+		require.NoError(t, err)
+		assert.Equal(t, `["a","b"]`, v)
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		var c JSONColumn[int64]
+		require.NoError(t, c.Scan([]byte(`9007199254740993`)))
+		assert.Equal(t, int64(9007199254740993), c.V)
+
+		var s JSONColumn[string]
+		require.NoError(t, s.Scan(`"<b>"`))
+		assert.Equal(t, "<b>", s.V)
+	})
+
+	t.Run("null", func(t *testing.T) {
+		c := NewJSONColumn(1)
+		for _, v := range []any{nil, "", []byte{}, "null", []byte("null")} {
+			c = NewJSONColumn(1)
+			require.NoError(t, c.Scan(v))
+			assert.False(t, c.Valid)
+			assert.Equal(t, 0, c.V)
+		}
+
+		v, err := c.Value()
+		require.NoError(t, err)
+		assert.Nil(t, v)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var c JSONColumn[int]
+		assert.EqualError(t, c.Scan(1), "unsupported scan type: int")
+		assert.EqualError(t, c.Scan(`"str"`), "json: cannot unmarshal string into Go value of type int")
+		assert.False(t, c.Valid)
+
+		_, err := NewJSONColumn(make(chan int)).Value()
+		assert.EqualError(t, err, "json: unsupported type: chan int")
+		_, err = NewCanonicalJSONColumn(make(chan int)).Value()
+		assert.EqualError(t, err, "json: unsupported type: chan int")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		type row struct {
+			Item  JSONColumn[jsonColumnItem] `json:"item"`
+			Empty JSONColumn[[]int]          `json:"empty"`
+		}
+		r := row{Item: NewJSONColumn(jsonColumnItem{Name: "a"})}
+		js, err := json.Marshal(r)
+		require.NoError(t, err)
+		assert.Equal(t, `{"item":{"name":"a"},"empty":null}`, string(js))
+
+		var r2 row
+		require.NoError(t, json.Unmarshal(js, &r2))
+		assert.Equal(t, r, r2)
+	})
+}
+
+func TestCanonicalJSONColumn(t *testing.T) {
+	c := NewCanonicalJSONColumn(map[string]any{"b": []any{2, 1}, "a": json.Number("1.50")})
+	v, err := c.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1.50,"b":[2,1]}`, v)
+
+	var c2 CanonicalJSONColumn[jsonColumnItem]
+	require.NoError(t, c2.Scan(`{"attrs":{"z":1,"y":2},"name":"n"}`))
+	assert.True(t, c2.Valid)
+	v, err = c2.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `{"attrs":{"y":2,"z":1},"name":"n"}`, v)
+
+	require.NoError(t, c2.Scan(nil))
+	v, err = c2.Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
@@ -0,0 +1,435 @@
+package values
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// JSON Patch operations, as defined in RFC 6902
+const (
+	PatchOpAdd     = "add"
+	PatchOpRemove  = "remove"
+	PatchOpReplace = "replace"
+	PatchOpMove    = "move"
+	PatchOpCopy    = "copy"
+	PatchOpTest    = "test"
+)
+
+// PatchOp is a single RFC 6902 JSON Patch operation
+type PatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler,
+// the value member is always present for add, replace and test operations,
+// even if it is null.
+func (op PatchOp) MarshalJSON() ([]byte, error) {
+	m := map[string]any{
+		"op":   op.Op,
+		"path": op.Path,
+	}
+	switch op.Op {
+	case PatchOpAdd, PatchOpReplace, PatchOpTest:
+		m["value"] = op.Value
+	case PatchOpMove, PatchOpCopy:
+		m["from"] = op.From
+	}
+	return json.Marshal(m)
+}
+
+// Diff returns RFC 6902 operations that transform a into b.
+// Keys are visited in sorted order, so the result is deterministic.
+// Values are compared by their canonical JSON representation.
+func Diff(a, b MapAny) []PatchOp {
+	var ops []PatchOp
+	return diffMaps(ops, "", a, b)
+}
+
+func diffMaps(ops []PatchOp, path string, a, b MapAny) []PatchOp {
+	for _, k := range a.OrderedKeys() {
+		if _, ok := b[k]; !ok {
+			ops = append(ops, PatchOp{Op: PatchOpRemove, Path: path + "/" + EscapeJSONPointer(k)})
+		}
+	}
+	for _, k := range b.OrderedKeys() {
+		p := path + "/" + EscapeJSONPointer(k)
+		av, ok := a[k]
+		if !ok {
+			ops = append(ops, PatchOp{Op: PatchOpAdd, Path: p, Value: b[k]})
+			continue
+		}
+		ops = diffValues(ops, p, av, b[k])
+	}
+	return ops
+}
+
+func diffValues(ops []PatchOp, path string, a, b any) []PatchOp {
+	if am, ok := CastMapAny(a); ok {
+		if bm, ok := CastMapAny(b); ok {
+			return diffMaps(ops, path, am, bm)
+		}
+	}
+	if as, ok := a.([]any); ok {
+		if bs, ok := b.([]any); ok {
+			return diffSlices(ops, path, as, bs)
+		}
+	}
+	if !jsonEqual(a, b) {
+		ops = append(ops, PatchOp{Op: PatchOpReplace, Path: path, Value: b})
+	}
+	return ops
+}
+
+func diffSlices(ops []PatchOp, path string, a, b []any) []PatchOp {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		ops = diffValues(ops, path+"/"+strconv.Itoa(i), a[i], b[i])
+	}
+	// remove from the tail, so the indexes stay valid
+	for i := len(a) - 1; i >= n; i-- {
+		ops = append(ops, PatchOp{Op: PatchOpRemove, Path: path + "/" + strconv.Itoa(i)})
+	}
+	for i := n; i < len(b); i++ {
+		ops = append(ops, PatchOp{Op: PatchOpAdd, Path: path + "/" + strconv.Itoa(i), Value: b[i]})
+	}
+	return ops
+}
+
+// Apply applies RFC 6902 operations to doc and returns the patched document.
+// The operations are applied to a copy of doc, on failure the original
+// document is left untouched and the error describes the failed operation.
+func Apply(doc MapAny, ops []PatchOp) (MapAny, error) {
+	root := cloneJSONValue(doc)
+	var err error
+	for i, op := range ops {
+		root, err = applyOp(root, op)
+		if err != nil {
+			return doc, errors.Wrapf(err, "patch op %d: %s %q", i, op.Op, op.Path)
+		}
+	}
+
+	res, ok := CastMapAny(root)
+	if !ok {
+		return doc, errors.Errorf("patched document is not an object: %T", root)
+	}
+	return res, nil
+}
+
+func applyOp(root any, op PatchOp) (any, error) {
+	path, err := ParseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case PatchOpAdd:
+		return patchAdd(root, path, cloneJSONValue(op.Value))
+	case PatchOpRemove:
+		res, _, err := patchRemove(root, path)
+		return res, err
+	case PatchOpReplace:
+		if _, err := patchGet(root, path); err != nil {
+			return nil, err
+		}
+		return patchReplace(root, path, cloneJSONValue(op.Value))
+	case PatchOpMove:
+		from, err := ParseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.From == op.Path {
+			return root, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, errors.Errorf("cannot move %q into its own child", op.From)
+		}
+		res, val, err := patchRemove(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(res, path, val)
+	case PatchOpCopy:
+		from, err := ParseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		val, err := patchGet(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(root, path, cloneJSONValue(val))
+	case PatchOpTest:
+		val, err := patchGet(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(val, op.Value) {
+			return nil, errors.Errorf("test failed: expected %s, got %s", JSON(op.Value), JSON(val))
+		}
+		return root, nil
+	default:
+		return nil, errors.Errorf("unsupported operation: %q", op.Op)
+	}
+}
+
+func patchGet(node any, path []string) (any, error) {
+	for i, token := range path {
+		switch x := node.(type) {
+		case MapAny:
+			v, ok := x[token]
+			if !ok {
+				return nil, errors.Errorf("path not found: %q", joinJSONPointer(path[:i+1]))
+			}
+			node = v
+		case []any:
+			idx, err := sliceIndex(token, len(x), false)
+			if err != nil {
+				return nil, err
+			}
+			node = x[idx]
+		default:
+			return nil, errors.Errorf("path not found: %q", joinJSONPointer(path[:i+1]))
+		}
+	}
+	return node, nil
+}
+
+// patchAdd returns node with value added at path
+func patchAdd(node any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token := path[0]
+	switch x := node.(type) {
+	case MapAny:
+		if len(path) == 1 {
+			x[token] = value
+			return x, nil
+		}
+		child, ok := x[token]
+		if !ok {
+			return nil, errors.Errorf("path not found: %q", token)
+		}
+		child, err := patchAdd(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		x[token] = child
+		return x, nil
+	case []any:
+		if len(path) == 1 {
+			if token == "-" {
+				return append(x, value), nil
+			}
+			idx, err := sliceIndex(token, len(x), true)
+			if err != nil {
+				return nil, err
+			}
+			x = append(x, nil)
+			copy(x[idx+1:], x[idx:])
+			x[idx] = value
+			return x, nil
+		}
+		idx, err := sliceIndex(token, len(x), false)
+		if err != nil {
+			return nil, err
+		}
+		child, err := patchAdd(x[idx], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		x[idx] = child
+		return x, nil
+	default:
+		return nil, errors.Errorf("path not found: %q", token)
+	}
+}
+
+// patchReplace returns node with value at path replaced
+func patchReplace(node any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token := path[0]
+	switch x := node.(type) {
+	case MapAny:
+		child, err := patchReplace(x[token], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		x[token] = child
+		return x, nil
+	case []any:
+		idx, err := sliceIndex(token, len(x), false)
+		if err != nil {
+			return nil, err
+		}
+		child, err := patchReplace(x[idx], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		x[idx] = child
+		return x, nil
+	default:
+		return nil, errors.Errorf("path not found: %q", token)
+	}
+}
+
+// patchRemove returns node with value at path removed, and the removed value
+func patchRemove(node any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the document root")
+	}
+	token := path[0]
+	switch x := node.(type) {
+	case MapAny:
+		child, ok := x[token]
+		if !ok {
+			return nil, nil, errors.Errorf("path not found: %q", token)
+		}
+		if len(path) == 1 {
+			delete(x, token)
+			return x, child, nil
+		}
+		child, removed, err := patchRemove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		x[token] = child
+		return x, removed, nil
+	case []any:
+		idx, err := sliceIndex(token, len(x), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(path) == 1 {
+			removed := x[idx]
+			return append(x[:idx], x[idx+1:]...), removed, nil
+		}
+		child, removed, err := patchRemove(x[idx], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		x[idx] = child
+		return x, removed, nil
+	default:
+		return nil, nil, errors.Errorf("path not found: %q", token)
+	}
+}
+
+func sliceIndex(token string, size int, insert bool) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || token[0] == '+' || token[0] == '-' {
+		return 0, errors.Errorf("invalid array index: %q", token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, errors.Errorf("invalid array index: %q", token)
+	}
+	if idx > size || (!insert && idx == size) {
+		return 0, errors.Errorf("array index out of range: %d", idx)
+	}
+	return idx, nil
+}
+
+// ParseJSONPointer parses RFC 6901 JSON Pointer into unescaped reference tokens.
+// The empty pointer refers to the whole document and returns no tokens.
+func ParseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, errors.Errorf("invalid JSON pointer: %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = UnescapeJSONPointer(t)
+	}
+	return tokens, nil
+}
+
+// EscapeJSONPointer escapes a reference token for use in JSON Pointer
+func EscapeJSONPointer(token string) string {
+	return jsonPointerEscaper.Replace(token)
+}
+
+// UnescapeJSONPointer unescapes JSON Pointer reference token
+func UnescapeJSONPointer(token string) string {
+	return jsonPointerUnescaper.Replace(token)
+}
+
+var (
+	jsonPointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+func joinJSONPointer(tokens []string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteByte('/')
+		b.WriteString(EscapeJSONPointer(t))
+	}
+	return b.String()
+}
+
+// jsonEqual returns true if both values have the same canonical JSON representation
+func jsonEqual(a, b any) bool {
+	ab, err := MarshalCanonicalJSON(cloneJSONValue(a))
+	if err != nil {
+		return false
+	}
+	bb, err := MarshalCanonicalJSON(cloneJSONValue(b))
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ab, bb)
+}
+
+// cloneJSONValue returns a deep copy of JSON containers in v,
+// maps are returned as MapAny and slices as []any, scalars are returned as-is.
+func cloneJSONValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	if m, ok := CastMapAny(v); ok {
+		res := make(MapAny, len(m))
+		for k, val := range m {
+			res[k] = cloneJSONValue(val)
+		}
+		return res
+	}
+	if _, ok := v.([]byte); ok {
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return []any(nil)
+		}
+		res := make([]any, rv.Len())
+		for i := range res {
+			res[i] = cloneJSONValue(rv.Index(i).Interface())
+		}
+		return res
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		res := make(MapAny, rv.Len())
+		for _, k := range rv.MapKeys() {
+			res[k.String()] = cloneJSONValue(rv.MapIndex(k).Interface())
+		}
+		return res
+	default:
+		return v
+	}
+}
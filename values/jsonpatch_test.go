@@ -0,0 +1,289 @@
+package values
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JSONPointer(t *testing.T) {
+	tokens, err := ParseJSONPointer("")
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	tokens, err = ParseJSONPointer("/a~1b/c~0d/0/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a/b", "c~d", "0", ""}, tokens)
+
+	_, err = ParseJSONPointer("a/b")
+	assert.EqualError(t, err, `invalid JSON pointer: "a/b"`)
+
+	assert.Equal(t, "a~1b~0c", EscapeJSONPointer("a/b~c"))
+	assert.Equal(t, "a/b~c", UnescapeJSONPointer("a~1b~0c"))
+	assert.Equal(t, "~1", UnescapeJSONPointer("~01"))
+}
+
+func Test_PatchOp_JSON(t *testing.T) {
+	ops := []PatchOp{
+		{Op: PatchOpAdd, Path: "/a", Value: nil},
+		{Op: PatchOpRemove, Path: "/b"},
+		{Op: PatchOpMove, Path: "/c", From: "/d"},
+	}
+	js, err := json.Marshal(ops)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"},{"from":"/d","op":"move","path":"/c"}]`, string(js))
+
+	var ops2 []PatchOp
+	require.NoError(t, json.Unmarshal(js, &ops2))
+	assert.Equal(t, ops, ops2)
+}
+
+// rfc6902 Appendix A
+func Test_Apply_RFC6902(t *testing.T) {
+	tcases := []struct {
+		name string
+		doc  string
+		ops  string
+		exp  string
+		err  string
+	}{
+		{
+			name: "A.1 add object member",
+			doc:  `{"foo":"bar"}`,
+			ops:  `[{"op":"add","path":"/baz","value":"qux"}]`,
+			exp:  `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name: "A.2 add array element",
+			doc:  `{"foo":["bar","baz"]}`,
+			ops:  `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			exp:  `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name: "A.3 remove object member",
+			doc:  `{"baz":"qux","foo":"bar"}`,
+			ops:  `[{"op":"remove","path":"/baz"}]`,
+			exp:  `{"foo":"bar"}`,
+		},
+		{
+			name: "A.4 remove array element",
+			doc:  `{"foo":["bar","qux","baz"]}`,
+			ops:  `[{"op":"remove","path":"/foo/1"}]`,
+			exp:  `{"foo":["bar","baz"]}`,
+		},
+		{
+			name: "A.5 replace value",
+			doc:  `{"baz":"qux","foo":"bar"}`,
+			ops:  `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			exp:  `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name: "A.6 move value",
+			doc:  `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			ops:  `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			exp:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name: "A.7 move array element",
+			doc:  `{"foo":["all","grass","cows","eat"]}`,
+			ops:  `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			exp:  `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name: "A.8 test success",
+			doc:  `{"baz":"qux","foo":["a",2,"c"]}`,
+			ops:  `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			exp:  `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name: "A.9 test error",
+			doc:  `{"baz":"qux"}`,
+			ops:  `[{"op":"test","path":"/baz","value":"bar"}]`,
+			err:  `patch op 0: test "/baz": test failed: expected "bar", got "qux"`,
+		},
+		{
+			name: "A.10 add nested member",
+			doc:  `{"foo":"bar"}`,
+			ops:  `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			exp:  `{"child":{"grandchild":{}},"foo":"bar"}`,
+		},
+		{
+			name: "A.12 add to nonexistent target",
+			doc:  `{"foo":"bar"}`,
+			ops:  `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			err:  `patch op 0: add "/baz/bat": path not found: "baz"`,
+		},
+		{
+			name: "A.14 escape ordering",
+			doc:  `{"/":9,"~1":10}`,
+			ops:  `[{"op":"test","path":"/~01","value":10}]`,
+			exp:  `{"/":9,"~1":10}`,
+		},
+		{
+			name: "A.16 add array value",
+			doc:  `{"foo":["bar"]}`,
+			ops:  `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			exp:  `{"foo":["bar",["abc","def"]]}`,
+		},
+		{
+			name: "copy",
+			doc:  `{"foo":{"bar":[1,2]}}`,
+			ops:  `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"add","path":"/baz/bar/-","value":3}]`,
+			exp:  `{"baz":{"bar":[1,2,3]},"foo":{"bar":[1,2]}}`,
+		},
+		{
+			name: "replace root",
+			doc:  `{"foo":"bar"}`,
+			ops:  `[{"op":"replace","path":"","value":{"baz":1}}]`,
+			exp:  `{"baz":1}`,
+		},
+		{
+			name: "replace missing",
+			doc:  `{"foo":"bar"}`,
+			ops:  `[{"op":"replace","path":"/baz","value":1}]`,
+			err:  `patch op 0: replace "/baz": path not found: "/baz"`,
+		},
+		{
+			name: "remove missing",
+			doc:  `{"foo":[1]}`,
+			ops:  `[{"op":"remove","path":"/foo/1"}]`,
+			err:  `patch op 0: remove "/foo/1": array index out of range: 1`,
+		},
+		{
+			name: "invalid index",
+			doc:  `{"foo":[1]}`,
+			ops:  `[{"op":"add","path":"/foo/01","value":1}]`,
+			err:  `patch op 0: add "/foo/01": invalid array index: "01"`,
+		},
+		{
+			name: "move into child",
+			doc:  `{"foo":{"bar":1}}`,
+			ops:  `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`,
+			err:  `patch op 0: move "/foo/bar/baz": cannot move "/foo" into its own child`,
+		},
+		{
+			name: "root not object",
+			doc:  `{"foo":1}`,
+			ops:  `[{"op":"replace","path":"","value":[1]}]`,
+			err:  `patched document is not an object: []interface {}`,
+		},
+		{
+			name: "unsupported",
+			doc:  `{"foo":1}`,
+			ops:  `[{"op":"merge","path":"/foo"}]`,
+			err:  `patch op 0: merge "/foo": unsupported operation: "merge"`,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := FromJSON(tc.doc)
+			var ops []PatchOp
+			require.NoError(t, json.Unmarshal([]byte(tc.ops), &ops))
+
+			res, err := Apply(doc, ops)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, res.JSON())
+		})
+	}
+}
+
+func Test_Apply_Rollback(t *testing.T) {
+	doc := MapAny{
+		"name": "test",
+		"list": []string{"a", "b"},
+		"nested": map[string]any{
+			"count": 1,
+		},
+	}
+	orig := doc.JSON()
+
+	ops := []PatchOp{
+		{Op: PatchOpReplace, Path: "/name", Value: "changed"},
+		{Op: PatchOpAdd, Path: "/list/-", Value: "c"},
+		{Op: PatchOpRemove, Path: "/nested/count"},
+		{Op: PatchOpTest, Path: "/name", Value: "test"},
+	}
+	res, err := Apply(doc, ops)
+	require.Error(t, err)
+	assert.Equal(t, orig, res.JSON())
+	assert.Equal(t, orig, doc.JSON())
+
+	res, err = Apply(doc, ops[:3])
+	require.NoError(t, err)
+	assert.Equal(t, `{"list":["a","b","c"],"name":"changed","nested":{}}`, res.JSON())
+	assert.Equal(t, orig, doc.JSON())
+
+	res, err = Apply(nil, []PatchOp{{Op: PatchOpAdd, Path: "/a", Value: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, res.JSON())
+}
+
+func Test_Diff(t *testing.T) {
+	tcases := []struct {
+		name string
+		a    string
+		b    string
+		exp  string
+	}{
+		{
+			name: "equal",
+			a:    `{"a":1,"b":{"c":[1,2]}}`,
+			b:    `{"b":{"c":[1,2]},"a":1}`,
+			exp:  `null`,
+		},
+		{
+			name: "members",
+			a:    `{"a":1,"b":2,"c":"x~/"}`,
+			b:    `{"a":1,"c":"y","d":null}`,
+			exp:  `[{"op":"remove","path":"/b"},{"op":"replace","path":"/c","value":"y"},{"op":"add","path":"/d","value":null}]`,
+		},
+		{
+			name: "nested",
+			a:    `{"a":{"b":{"c":1,"d/e":2}}}`,
+			b:    `{"a":{"b":{"c":2,"d/e":2}}}`,
+			exp:  `[{"op":"replace","path":"/a/b/c","value":2}]`,
+		},
+		{
+			name: "array shrink",
+			a:    `{"a":[1,2,3,4]}`,
+			b:    `{"a":[1,5]}`,
+			exp:  `[{"op":"replace","path":"/a/1","value":5},{"op":"remove","path":"/a/3"},{"op":"remove","path":"/a/2"}]`,
+		},
+		{
+			name: "array grow",
+			a:    `{"a":[{"x":1}]}`,
+			b:    `{"a":[{"x":2},{"y":3}]}`,
+			exp:  `[{"op":"replace","path":"/a/0/x","value":2},{"op":"add","path":"/a/1","value":{"y":3}}]`,
+		},
+		{
+			name: "type change",
+			a:    `{"a":{"b":1}}`,
+			b:    `{"a":[1]}`,
+			exp:  `[{"op":"replace","path":"/a","value":[1]}]`,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := FromJSON(tc.a)
+			b := FromJSON(tc.b)
+			ops := Diff(a, b)
+			assert.Equal(t, tc.exp, JSON(ops))
+
+			res, err := Apply(a, ops)
+			require.NoError(t, err)
+			assert.Equal(t, b.JSON(), res.JSON())
+		})
+	}
+
+	// typed values are compared by their JSON representation
+	ops := Diff(MapAny{"a": []int{1, 2}, "b": 1}, MapAny{"a": []any{1, 2}, "b": float64(1)})
+	assert.Empty(t, ops)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -17,7 +18,32 @@ import (
 // MapAny provides map of values
 type MapAny map[string]any
 
-func FromStruct(value any) MapAny {
+// StructOptions configures FromStruct conversion
+type StructOptions struct {
+	// TagName specifies the struct tag used for key names, e.g. "json" or "yaml".
+	// When set, fields tagged with "-" are skipped, and empty values of fields
+	// tagged with omitempty (or zero values with omitzero) are omitted.
+	// When empty, Go field names are used.
+	TagName string
+	// Recursive converts nested structs, pointers, maps with string keys and slices
+	// into MapAny and []any, so they can be traversed with Map, Extract and TraverseSubMaps.
+	// Values implementing json.Marshaler, such as time.Time, are kept as-is.
+	Recursive bool
+	// FlattenEmbedded promotes fields of embedded structs without a tag name
+	// to the parent map, as encoding/json does.
+	FlattenEmbedded bool
+
+	// shrinkKeep ignores omitempty and omitzero of the fields tagged with `shrink:"keep"`
+	shrinkKeep bool
+}
+
+// JSONStructOptions produces a map with the same keys and shape as json.Marshal
+var JSONStructOptions = StructOptions{TagName: "json", Recursive: true, FlattenEmbedded: true}
+
+// FromStruct returns map of the struct fields.
+// By default, keys are Go field names and values are stored as-is,
+// see StructOptions to derive keys from tags and convert nested values.
+func FromStruct(value any, opts ...StructOptions) MapAny {
 	if value == nil {
 		return nil
 	}
@@ -35,18 +61,167 @@ func FromStruct(value any) MapAny {
 		panic(fmt.Sprintf("expected struct, got %s", v.Kind()))
 	}
 
-	typ := v.Type()
+	var o StructOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
 
 	res := make(MapAny, v.NumField())
+	o.structToMap(res, v)
+	return res
+}
+
+func (o StructOptions) structToMap(res MapAny, v reflect.Value) {
+	typ := v.Type()
+	// fields of embedded structs do not override the fields of the parent
+	var embedded []reflect.Value
 	for i := 0; i < v.NumField(); i++ {
+		sf := typ.Field(i)
 		field := v.Field(i)
+
+		name, omitEmpty, omitZero, skip := o.fieldTag(sf)
+		if skip {
+			continue
+		}
+
+		if o.FlattenEmbedded && sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				if !sf.IsExported() || field.IsNil() {
+					continue
+				}
+				ft = ft.Elem()
+				field = field.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, field)
+				continue
+			}
+		}
+
 		// Skip unexported struct fields that cannot be interfaced to avoid panics.
 		if !field.CanInterface() {
 			continue
 		}
-		res[typ.Field(i).Name] = field.Interface()
+		if o.shrinkKeep && isShrinkKeep(sf) {
+			omitEmpty, omitZero = false, false
+		}
+		if (omitEmpty && isEmptyValue(field)) || (omitZero && field.IsZero()) {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if o.Recursive {
+			res[name] = o.convert(field)
+		} else {
+			res[name] = field.Interface()
+		}
+	}
+
+	for _, field := range embedded {
+		em := make(MapAny, field.NumField())
+		o.structToMap(em, field)
+		for k, val := range em {
+			if _, exists := res[k]; !exists {
+				res[k] = val
+			}
+		}
 	}
-	return res
+}
+
+// fieldTag returns the key name and options from the struct tag
+func (o StructOptions) fieldTag(sf reflect.StructField) (name string, omitEmpty, omitZero, skip bool) {
+	if o.TagName == "" {
+		return "", false, false, false
+	}
+	tag, ok := sf.Tag.Lookup(o.TagName)
+	if !ok {
+		return "", false, false, false
+	}
+	if tag == "-" {
+		return "", false, false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "omitempty":
+			omitEmpty = true
+		case "omitzero":
+			omitZero = true
+		}
+	}
+	return name, omitEmpty, omitZero, false
+}
+
+var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+// convert returns the value with nested structs, maps and slices converted to MapAny and []any
+func (o StructOptions) convert(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type().Implements(jsonMarshalerType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil
+		}
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return o.convert(v.Elem())
+	case reflect.Struct:
+		res := make(MapAny, v.NumField())
+		o.structToMap(res, v)
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		res := make(MapAny, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res[iter.Key().String()] = o.convert(iter.Value())
+		}
+		return res
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// keep []byte, it is encoded as base64 string
+			return v.Interface()
+		}
+		res := make([]any, v.Len())
+		for i := range res {
+			res[i] = o.convert(v.Index(i))
+		}
+		return res
+	default:
+		return v.Interface()
+	}
+}
+
+// isEmptyValue reports whether v is empty, as defined by encoding/json omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
 }
 
 // FromJSON returns map from json encoded string,
@@ -417,9 +592,15 @@ func (c MapAny) Value() (driver.Value, error) {
 	return string(value), nil
 }
 
-func (c MapAny) CanonicalJSON() ([]byte, error) {
+// CanonicalJSON returns canonical JSON representation of the map,
+// by default WriteCanonicalJSON is used, pass CanonicalJCS for RFC 8785 output.
+func (c MapAny) CanonicalJSON(form ...CanonicalForm) ([]byte, error) {
 	var buf bytes.Buffer
-	if err := WriteCanonicalJSON(&buf, c); err != nil {
+	write := WriteCanonicalJSON
+	if len(form) > 0 && form[0] == CanonicalJCS {
+		write = WriteJCS
+	}
+	if err := write(&buf, c); err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
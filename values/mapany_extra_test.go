@@ -1,6 +1,9 @@
 package values
 
 import (
+	"encoding/json"
+	"time"
+
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -126,3 +129,103 @@ func TestMapAnyFromProtoValue(t *testing.T) {
 		})
 	}
 }
+
+type fromStructBase struct {
+	ID      string `json:"id" yaml:"id"`
+	Version int    `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+type FromStructMeta struct {
+	Owner string `json:"owner" yaml:"owner"`
+}
+
+type fromStructChild struct {
+	Name  string            `json:"name" yaml:"name"`
+	Tags  []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Attrs map[string]string `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+}
+
+type fromStructParent struct {
+	fromStructBase
+	*FromStructMeta
+	ID       string                      `json:"parent_id" yaml:"parentId"`
+	Name     string                      `json:"name" yaml:"name"`
+	Count    int                         `json:"count,omitempty" yaml:"count,omitempty"`
+	Enabled  bool                        `json:"enabled" yaml:"enabled"`
+	Child    fromStructChild             `json:"child" yaml:"child"`
+	ChildPtr *fromStructChild            `json:"child_ptr,omitempty" yaml:"childPtr,omitempty"`
+	Children []fromStructChild           `json:"children" yaml:"children"`
+	ByName   map[string]*fromStructChild `json:"by_name" yaml:"byName"`
+	Created  time.Time                   `json:"created" yaml:"created"`
+	Raw      []byte                      `json:"raw,omitempty" yaml:"raw,omitempty"`
+	Zero     fromStructChild             `json:"zero,omitzero" yaml:"zero,omitempty"`
+	Dash     string                      `json:"-," yaml:"-"`
+	Skipped  string                      `json:"-" yaml:"-"`
+	NoTag    string
+	private  string
+}
+
+func TestFromStructOptions(t *testing.T) {
+	t.Parallel()
+
+	val := &fromStructParent{
+		fromStructBase: fromStructBase{ID: "base", Version: 1},
+		FromStructMeta: &FromStructMeta{Owner: "me"},
+		ID:             "parent",
+		Name:           "name",
+		Child:          fromStructChild{Name: "child", Tags: []string{"a"}},
+		ChildPtr:       &fromStructChild{Name: "ptr", Attrs: map[string]string{"k": "v"}},
+		Children:       []fromStructChild{{Name: "c1"}, {Name: "c2"}},
+		ByName:         map[string]*fromStructChild{"n": {Name: "n"}, "nil": nil},
+		Created:        time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Raw:            []byte("raw"),
+		Dash:           "dash",
+		Skipped:        "skipped",
+		NoTag:          "notag",
+		private:        "private",
+	}
+
+	exp, err := json.Marshal(val)
+	require.NoError(t, err)
+
+	m := FromStruct(val, JSONStructOptions)
+	assert.JSONEq(t, string(exp), m.JSON())
+	assert.Equal(t, "child", m.Map("child").String("name"))
+	assert.Equal(t, "v", m.Extract("child_ptr", "attrs").String("k"))
+	assert.Equal(t, "me", m.String("owner"))
+	assert.Equal(t, "base", m.String("id"))
+	assert.Equal(t, "parent", m.String("parent_id"))
+	assert.False(t, m.Has("count"))
+	assert.False(t, m.Has("zero"))
+	assert.True(t, m.Has("enabled"))
+	assert.IsType(t, time.Time{}, m["created"])
+
+	var names []string
+	require.NoError(t, m.TraverseSubMaps(func(k string, v MapAny) (bool, error) {
+		if n := v.String("name"); n != "" {
+			names = append(names, n)
+		}
+		return true, nil
+	}))
+	assert.ElementsMatch(t, []string{"child", "ptr", "n"}, names)
+	assert.Equal(t, "c2", m.Slice("children")[1].(MapAny).String("name"))
+
+	ym := FromStruct(val, StructOptions{TagName: "yaml", Recursive: true, FlattenEmbedded: true})
+	assert.Equal(t, "parent", ym.String("parentId"))
+	assert.Equal(t, "ptr", ym.Map("childPtr").String("name"))
+	assert.False(t, ym.Has("-"))
+	assert.False(t, ym.Has("Dash"))
+
+	// tags without recursion keep nested values as-is
+	m = FromStruct(val, StructOptions{TagName: "json"})
+	assert.Equal(t, val.Child, m["child"])
+	assert.Equal(t, val.FromStructMeta, m["FromStructMeta"])
+	assert.False(t, m.Has("fromStructBase"))
+	assert.Equal(t, "dash", m["-"])
+
+	// default uses field names
+	m = FromStruct(val)
+	assert.Equal(t, "parent", m["ID"])
+	assert.Equal(t, "skipped", m["Skipped"])
+	assert.Equal(t, val.FromStructMeta, m["FromStructMeta"])
+}
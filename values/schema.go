@@ -0,0 +1,433 @@
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// JSON Schema types
+const (
+	SchemaTypeObject  = "object"
+	SchemaTypeArray   = "array"
+	SchemaTypeString  = "string"
+	SchemaTypeNumber  = "number"
+	SchemaTypeInteger = "integer"
+	SchemaTypeBoolean = "boolean"
+	SchemaTypeNull    = "null"
+)
+
+// JSON Schema formats detected by InferSchema and checked by Validate
+const (
+	SchemaFormatDateTime = "date-time"
+	SchemaFormatDate     = "date"
+	SchemaFormatUUID     = "uuid"
+)
+
+// SchemaDraft is the JSON Schema version of inferred schemas
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a subset of JSON Schema describing the shape of MapAny documents
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Type                 SchemaTypes        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// SchemaTypes is a list of allowed types,
+// encoded as a string when it has a single type
+type SchemaTypes []string
+
+// Has returns true if typ is allowed
+func (t SchemaTypes) Has(typ string) bool {
+	for _, s := range t {
+		if s == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON implements json.Marshaler
+func (t SchemaTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *SchemaTypes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = SchemaTypes{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.WithStack(err)
+	}
+	*t = list
+	return nil
+}
+
+// InferOptions configures InferSchemaWith
+type InferOptions struct {
+	// MaxEnumValues is the maximum number of distinct string values
+	// to infer an enum. Enum is inferred only if some values repeat
+	// across the samples, and the values do not have a format.
+	// Zero disables enums.
+	MaxEnumValues int
+}
+
+// DefaultInferOptions is used by InferSchema
+var DefaultInferOptions = InferOptions{MaxEnumValues: 5}
+
+// InferSchema returns JSON Schema describing all samples, see InferSchemaWith
+func InferSchema(samples ...MapAny) *Schema {
+	return InferSchemaWith(DefaultInferOptions, samples...)
+}
+
+// InferSchemaWith returns JSON Schema describing all samples:
+// types of values, keys present in every object as required,
+// enums for strings with few distinct values,
+// and date-time, date and uuid formats of strings.
+// Integers are reported as "number" if any sample has a fractional value.
+func InferSchemaWith(opts InferOptions, samples ...MapAny) *Schema {
+	root := &inferNode{}
+	for _, m := range samples {
+		root.add(jsonValue(m), opts)
+	}
+	s := root.schema(opts)
+	s.Schema = SchemaDraft
+	return s
+}
+
+type inferNode struct {
+	types map[string]bool
+	// objects is the number of objects, and propCount the number of objects with the key
+	objects   int
+	props     map[string]*inferNode
+	propCount map[string]int
+	items     *inferNode
+	// strings is the number of string values, and values the distinct values
+	strings int
+	values  map[string]bool
+	format  string
+	// mixedFormats is set when strings have different formats
+	mixedFormats bool
+	// noEnum is set when strings include time or bytes values
+	noEnum bool
+}
+
+func (n *inferNode) add(v any, opts InferOptions) {
+	v = jsonScalar(v)
+	typ := schemaType(v)
+	if n.types == nil {
+		n.types = map[string]bool{}
+	}
+	n.types[typ] = true
+
+	switch typ {
+	case SchemaTypeObject:
+		m := v.(MapAny)
+		if n.props == nil {
+			n.props = map[string]*inferNode{}
+			n.propCount = map[string]int{}
+		}
+		n.objects++
+		for k, val := range m {
+			child := n.props[k]
+			if child == nil {
+				child = &inferNode{}
+				n.props[k] = child
+			}
+			n.propCount[k]++
+			child.add(val, opts)
+		}
+	case SchemaTypeArray:
+		for _, item := range v.([]any) {
+			if n.items == nil {
+				n.items = &inferNode{}
+			}
+			n.items.add(item, opts)
+		}
+	case SchemaTypeString:
+		f := stringFormat(v)
+		if n.strings == 0 {
+			n.format = f
+		} else if n.format != f {
+			n.mixedFormats = true
+		}
+		n.strings++
+		s, ok := v.(string)
+		if !ok {
+			n.noEnum = true
+		} else if len(n.values) <= opts.MaxEnumValues {
+			// collect one more value than allowed to detect high cardinality
+			if n.values == nil {
+				n.values = map[string]bool{}
+			}
+			n.values[s] = true
+		}
+	}
+}
+
+// schemaTypeOrder is the order of types in inferred schemas
+var schemaTypeOrder = []string{
+	SchemaTypeObject, SchemaTypeArray, SchemaTypeString,
+	SchemaTypeNumber, SchemaTypeInteger, SchemaTypeBoolean, SchemaTypeNull,
+}
+
+func (n *inferNode) schema(opts InferOptions) *Schema {
+	s := &Schema{}
+	if n.types[SchemaTypeNumber] {
+		delete(n.types, SchemaTypeInteger)
+	}
+	for _, t := range schemaTypeOrder {
+		if n.types[t] {
+			s.Type = append(s.Type, t)
+		}
+	}
+
+	if n.objects > 0 {
+		s.Properties = make(map[string]*Schema, len(n.props))
+		for k, child := range n.props {
+			s.Properties[k] = child.schema(opts)
+			if n.propCount[k] == n.objects {
+				s.Required = append(s.Required, k)
+			}
+		}
+		sort.Strings(s.Required)
+	}
+	if n.items != nil {
+		s.Items = n.items.schema(opts)
+	}
+	if n.strings > 0 {
+		if !n.mixedFormats {
+			s.Format = n.format
+		}
+		// strings, optionally nullable, with repeating values
+		stringsOnly := len(n.types) == 1 || (len(n.types) == 2 && n.types[SchemaTypeNull])
+		if s.Format == "" && stringsOnly && !n.noEnum && opts.MaxEnumValues > 0 &&
+			len(n.values) <= opts.MaxEnumValues && n.strings > len(n.values) {
+			s.Enum = n.enum()
+		}
+	}
+	return s
+}
+
+func (n *inferNode) enum() []any {
+	keys := make([]string, 0, len(n.values))
+	for k := range n.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	enum := make([]any, 0, len(keys)+1)
+	for _, k := range keys {
+		enum = append(enum, k)
+	}
+	if n.types[SchemaTypeNull] {
+		enum = append(enum, nil)
+	}
+	return enum
+}
+
+// ValidationError is returned by Schema.Validate and contains all violations
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error implements error interface
+func (e *ValidationError) Error() string {
+	list := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		list[i] = fe.Error()
+	}
+	return strings.Join(list, "; ")
+}
+
+// Validate checks the value, usually MapAny, against the schema,
+// and returns *ValidationError with every violation and its path.
+// Supported keywords are type, format, enum, properties, required,
+// additionalProperties and items. Unknown formats are not checked.
+func (s *Schema) Validate(v any) error {
+	var errs []FieldError
+	s.validate("", jsonValue(v), &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func (s *Schema) validate(path string, v any, errs *[]FieldError) {
+	fail := func(path, msg string, args ...any) {
+		*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf(msg, args...)})
+	}
+
+	v = jsonScalar(v)
+	typ := schemaType(v)
+	if typ == "" {
+		fail(path, "unsupported type %T", v)
+		return
+	}
+	if len(s.Type) > 0 && !s.Type.Has(typ) && !(typ == SchemaTypeInteger && s.Type.Has(SchemaTypeNumber)) {
+		fail(path, "expected %s, got %s", strings.Join(s.Type, " or "), typ)
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail(path, "value %s is not one of %s", JSON(v), JSON(s.Enum))
+		}
+	}
+
+	switch typ {
+	case SchemaTypeString:
+		if isKnownFormat(s.Format) && stringFormat(v) != s.Format {
+			fail(path, "expected %s format, got %s", s.Format, JSON(v))
+		}
+	case SchemaTypeObject:
+		m := v.(MapAny)
+		for _, k := range s.Required {
+			if _, ok := m[k]; !ok {
+				fail(joinPath(path, k), "required key is missing")
+			}
+		}
+		for _, k := range m.OrderedKeys() {
+			if ps, ok := s.Properties[k]; ok {
+				ps.validate(joinPath(path, k), m[k], errs)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				fail(joinPath(path, k), "unexpected key")
+			}
+		}
+	case SchemaTypeArray:
+		if s.Items != nil {
+			for i, item := range v.([]any) {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	}
+}
+
+// jsonValue returns v with structs, maps and slices converted to MapAny and []any
+func jsonValue(v any) any {
+	return JSONStructOptions.convert(reflect.ValueOf(v))
+}
+
+// jsonScalar returns values that are not JSON types,
+// such as custom json.Marshaler, in their JSON form
+func jsonScalar(v any) any {
+	switch v.(type) {
+	case nil, bool, string, json.Number, float64, MapAny, []any, []byte, time.Time:
+		return v
+	}
+	if schemaType(v) != "" {
+		return v
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var res any
+	d := json.NewDecoder(strings.NewReader(string(b)))
+	d.UseNumber()
+	if err = d.Decode(&res); err != nil {
+		return v
+	}
+	return jsonValue(res)
+}
+
+// schemaType returns JSON Schema type of the value,
+// or empty string if the type is not supported
+func schemaType(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return SchemaTypeNull
+	case string, []byte, time.Time:
+		return SchemaTypeString
+	case json.Number:
+		f, err := x.Float64()
+		if err == nil && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return SchemaTypeInteger
+		}
+		return SchemaTypeNumber
+	case MapAny:
+		return SchemaTypeObject
+	case []any:
+		return SchemaTypeArray
+	}
+
+	rv := reflect.ValueOf(v)
+	if _, ok := v.(json.Marshaler); ok {
+		return ""
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return SchemaTypeBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return SchemaTypeInteger
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return SchemaTypeInteger
+		}
+		return SchemaTypeNumber
+	case reflect.String:
+		return SchemaTypeString
+	}
+	return ""
+}
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func isKnownFormat(f string) bool {
+	return f == SchemaFormatDateTime || f == SchemaFormatDate || f == SchemaFormatUUID
+}
+
+// stringFormat returns the detected format of the string value
+func stringFormat(v any) string {
+	var s string
+	switch x := v.(type) {
+	case time.Time:
+		return SchemaFormatDateTime
+	case string:
+		s = x
+	default:
+		return ""
+	}
+
+	switch {
+	case uuidRegex.MatchString(s):
+		return SchemaFormatUUID
+	case len(s) == len(time.DateOnly):
+		if _, err := time.Parse(time.DateOnly, s); err == nil {
+			return SchemaFormatDate
+		}
+	case len(s) > len(time.DateOnly):
+		if _, err := time.Parse(time.RFC3339, s); err == nil {
+			return SchemaFormatDateTime
+		}
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package values
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferSchema(t *testing.T) {
+	var samples []MapAny
+	for _, js := range []string{
+		`{"id":"6f1c2a9e-3b4d-4c5e-8f9a-0b1c2d3e4f5a","status":"active","count":1,"ratio":1,"created":"2024-01-02T03:04:05Z","day":"2024-01-02","tags":["a"],"owner":{"name":"n","email":"a@b.c"},"note":null}`,
+		`{"id":"7f1c2a9e-3b4d-4c5e-8f9a-0b1c2d3e4f5a","status":"inactive","count":2,"ratio":0.5,"created":"2024-01-03T03:04:05.123+02:00","day":"2024-01-03","tags":[],"owner":{"name":"m"},"note":"x"}`,
+		`{"id":"8f1c2a9e-3b4d-4c5e-8f9a-0b1c2d3e4f5a","status":"active","count":3,"ratio":2,"created":"2024-01-04T03:04:05Z","day":"2024-01-04","tags":["b",1],"mixed":"2024-01-04"}`,
+		`{"id":"9f1c2a9e-3b4d-4c5e-8f9a-0b1c2d3e4f5a","status":"active","count":4,"ratio":3,"created":"2024-01-05T03:04:05Z","day":"2024-01-05","tags":null,"mixed":"x"}`,
+	} {
+		samples = append(samples, FromJSON(js))
+	}
+
+	s := InferSchema(samples...)
+	js, err := json.MarshalIndent(s, "", "  ")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"count": {"type": "integer"},
+			"created": {"type": "string", "format": "date-time"},
+			"day": {"type": "string", "format": "date"},
+			"id": {"type": "string", "format": "uuid"},
+			"mixed": {"type": "string"},
+			"note": {"type": ["string", "null"]},
+			"owner": {
+				"type": "object",
+				"properties": {
+					"email": {"type": "string"},
+					"name": {"type": "string"}
+				},
+				"required": ["name"]
+			},
+			"ratio": {"type": "number"},
+			"status": {"type": "string", "enum": ["active", "inactive"]},
+			"tags": {
+				"type": ["array", "null"],
+				"items": {"type": ["string", "integer"]}
+			}
+		},
+		"required": ["count", "created", "day", "id", "ratio", "status", "tags"]
+	}`, string(js))
+
+	var s2 Schema
+	require.NoError(t, json.Unmarshal(js, &s2))
+	assert.Equal(t, s, &s2)
+
+	for _, m := range samples {
+		assert.NoError(t, s.Validate(m))
+	}
+
+	// enums
+	s = InferSchemaWith(InferOptions{MaxEnumValues: 1}, samples...)
+	assert.Empty(t, s.Properties["status"].Enum)
+	s = InferSchemaWith(InferOptions{}, samples...)
+	assert.Empty(t, s.Properties["status"].Enum)
+	s = InferSchema(MapAny{"a": "x"}, MapAny{"a": "x"}, MapAny{"a": nil})
+	assert.Equal(t, []any{"x", nil}, s.Properties["a"].Enum)
+	s = InferSchema(MapAny{"a": "x"}, MapAny{"a": "y"})
+	assert.Empty(t, s.Properties["a"].Enum)
+
+	// Go values
+	type item struct {
+		Name    string    `json:"name"`
+		Created time.Time `json:"created"`
+		Size    uint16    `json:"size"`
+		Data    []byte    `json:"data,omitempty"`
+	}
+	s = InferSchema(MapAny{"items": []item{{Name: "a", Data: []byte{1}}}, "score": json.Number("1.5")})
+	assert.Equal(t, SchemaTypes{"object"}, s.Type)
+	assert.Equal(t, []string{"items", "score"}, s.Required)
+	assert.Equal(t, SchemaTypes{"number"}, s.Properties["score"].Type)
+	items := s.Properties["items"].Items
+	require.NotNil(t, items)
+	assert.Equal(t, []string{"created", "data", "name", "size"}, items.Required)
+	assert.Equal(t, "date-time", items.Properties["created"].Format)
+	assert.Equal(t, SchemaTypes{"integer"}, items.Properties["size"].Type)
+	assert.Equal(t, SchemaTypes{"string"}, items.Properties["data"].Type)
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s := &Schema{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"status": {"type": "string", "enum": ["active", "inactive"]},
+			"count": {"type": "integer"},
+			"ratio": {"type": "number"},
+			"created": {"type": "string", "format": "date-time"},
+			"day": {"type": "string", "format": "date"},
+			"custom": {"type": "string", "format": "hostname"},
+			"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+			"owner": {
+				"type": "object",
+				"properties": {"name": {"type": "string"}},
+				"required": ["name"],
+				"additionalProperties": false
+			}
+		},
+		"required": ["id", "status"]
+	}`), s))
+
+	assert.NoError(t, s.Validate(MapAny{
+		"id":      "6f1c2a9e-3b4d-4c5e-8f9a-0b1c2d3e4f5a",
+		"status":  "active",
+		"count":   json.Number("2"),
+		"ratio":   2,
+		"created": time.Now(),
+		"day":     "2024-01-02",
+		"custom":  "anything",
+		"tags":    nil,
+		"owner":   map[string]any{"name": "n"},
+		"extra":   true,
+	}))
+
+	err := s.Validate(MapAny{
+		"status":  "deleted",
+		"count":   1.5,
+		"ratio":   "1",
+		"created": "2024-01-02",
+		"day":     "2024-01-02T00:00:00Z",
+		"tags":    []any{"a", 1, []string{}},
+		"owner":   MapAny{"email": "e"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, `id: required key is missing; `+
+		`count: expected integer, got number; `+
+		`created: expected date-time format, got "2024-01-02"; `+
+		`day: expected date format, got "2024-01-02T00:00:00Z"; `+
+		`owner.name: required key is missing; `+
+		`owner.email: unexpected key; `+
+		`ratio: expected number, got string; `+
+		`status: value "deleted" is not one of ["active","inactive"]; `+
+		`tags[1]: expected string, got integer; `+
+		`tags[2]: expected string, got array`, err.Error())
+
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Len(t, verr.Errors, 10)
+	assert.Equal(t, "id", verr.Errors[0].Path)
+
+	err = s.Validate([]any{})
+	assert.EqualError(t, err, "expected object, got array")
+
+	err = s.Validate(MapAny{"id": make(chan int), "status": "active"})
+	assert.EqualError(t, err, "id: unsupported type chan int")
+}
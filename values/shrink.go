@@ -0,0 +1,208 @@
+package values
+
+import (
+	"reflect"
+)
+
+// ShrinkPolicy configures which values are treated as empty by ShrinkWith.
+// Nil values, and empty maps, slices and structs are always removed.
+type ShrinkPolicy struct {
+	// KeepZeroNumbers keeps numbers with zero value, such as `replicas: 0`
+	KeepZeroNumbers bool
+	// KeepFalse keeps boolean values set to false
+	KeepFalse bool
+	// KeepEmptyStrings keeps empty strings
+	KeepEmptyStrings bool
+	// MaxDepth limits the number of nested levels to shrink,
+	// maps, slices and structs below this level are returned as-is.
+	// Zero means no limit.
+	MaxDepth int
+	// TagName specifies the struct tag used for key names, "json" by default
+	TagName string
+}
+
+// ShrinkWith removes empty values recursively as Shrink does,
+// using the policy to decide which values are empty.
+// Maps with string keys and structs are returned as MapAny, slices as []any.
+// Struct keys are derived from the TagName tag, and fields tagged
+// with `shrink:"keep"` are kept as-is, even if empty or tagged with omitempty.
+// Values implementing json.Marshaler, such as time.Time, are returned as-is,
+// unless they are zero.
+func ShrinkWith(value any, policy ShrinkPolicy) any {
+	return policy.shrink(reflect.ValueOf(value), 0)
+}
+
+// ShrinkWith shrinks the map by removing empty keys, see ShrinkWith
+func (c MapAny) ShrinkWith(policy ShrinkPolicy) MapAny {
+	res, _ := ShrinkWith(c, policy).(MapAny)
+	return res
+}
+
+func (p ShrinkPolicy) structOptions() StructOptions {
+	o := StructOptions{TagName: p.TagName, FlattenEmbedded: true, shrinkKeep: true}
+	if o.TagName == "" {
+		o.TagName = "json"
+	}
+	return o
+}
+
+func (p ShrinkPolicy) shrink(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		return p.shrink(v.Elem(), depth)
+	}
+	if v.Type().Implements(jsonMarshalerType) {
+		if v.IsZero() {
+			return nil
+		}
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if !v.Bool() && !p.KeepFalse {
+			return nil
+		}
+		return v.Interface()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		if v.IsZero() && !p.KeepZeroNumbers {
+			return nil
+		}
+		return v.Interface()
+	case reflect.String:
+		if v.Len() == 0 && !p.KeepEmptyStrings {
+			return nil
+		}
+		return v.Interface()
+	case reflect.Map, reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return nil
+		}
+	case reflect.Struct:
+	default:
+		return v.Interface()
+	}
+
+	if p.MaxDepth > 0 && depth >= p.MaxDepth {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		return p.shrinkMap(v, depth)
+	case reflect.Struct:
+		return p.shrinkStruct(v, depth)
+	default:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// keep []byte, it is encoded as base64 string
+			return v.Interface()
+		}
+		var res []any
+		for i := 0; i < v.Len(); i++ {
+			if sv := p.shrink(v.Index(i), depth+1); sv != nil {
+				res = append(res, sv)
+			}
+		}
+		if len(res) == 0 {
+			return nil
+		}
+		return res
+	}
+}
+
+func (p ShrinkPolicy) shrinkMap(v reflect.Value, depth int) any {
+	if v.Type().Key().Kind() != reflect.String {
+		res := reflect.MakeMap(v.Type())
+		elemType := v.Type().Elem()
+		iter := v.MapRange()
+		for iter.Next() {
+			sv := p.shrink(iter.Value(), depth+1)
+			if sv == nil {
+				continue
+			}
+			val := reflect.ValueOf(sv)
+			if !val.Type().AssignableTo(elemType) {
+				// nested structs and maps are converted, keep the original value
+				val = iter.Value()
+			}
+			res.SetMapIndex(iter.Key(), val)
+		}
+		if res.Len() == 0 {
+			return nil
+		}
+		return res.Interface()
+	}
+
+	res := make(MapAny, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		if sv := p.shrink(iter.Value(), depth+1); sv != nil {
+			res[iter.Key().String()] = sv
+		}
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+func (p ShrinkPolicy) shrinkStruct(v reflect.Value, depth int) any {
+	o := p.structOptions()
+	fields := make(MapAny, v.NumField())
+	o.structToMap(fields, v)
+
+	keep := map[string]bool{}
+	o.keepFields(v.Type(), keep)
+
+	res := make(MapAny, len(fields))
+	for k, val := range fields {
+		if keep[k] {
+			res[k] = val
+		} else if sv := p.shrink(reflect.ValueOf(val), depth+1); sv != nil {
+			res[k] = sv
+		}
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+// keepFields collects key names of the fields tagged with `shrink:"keep"`
+func (o StructOptions) keepFields(typ reflect.Type, keep map[string]bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		name, _, _, skip := o.fieldTag(sf)
+		if skip {
+			continue
+		}
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				o.keepFields(ft, keep)
+				continue
+			}
+		}
+		if isShrinkKeep(sf) {
+			if name == "" {
+				name = sf.Name
+			}
+			keep[name] = true
+		}
+	}
+}
+
+// isShrinkKeep returns true if the field is tagged with `shrink:"keep"`
+func isShrinkKeep(sf reflect.StructField) bool {
+	return sf.Tag.Get("shrink") == "keep"
+}
@@ -2,6 +2,7 @@ package values
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -425,3 +426,138 @@ func TestShrinkPreservesTypes(t *testing.T) {
 		assert.Nil(t, Shrink(float64(0)))
 	})
 }
+
+type shrinkSpec struct {
+	Replicas int            `json:"replicas"`
+	Paused   bool           `json:"paused"`
+	Image    string         `json:"image,omitempty"`
+	Minimum  int            `json:"minimum,omitempty" shrink:"keep"`
+	Labels   map[string]any `json:"labels" shrink:"keep"`
+	Secret   string         `json:"-"`
+	Started  time.Time      `json:"started"`
+	Owner    *shrinkOwner   `json:"owner"`
+	shrinkMeta
+}
+
+type shrinkOwner struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type shrinkMeta struct {
+	Version int    `json:"version" shrink:"keep"`
+	Comment string `json:"comment"`
+}
+
+func TestShrinkWith(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		input    any
+		policy   ShrinkPolicy
+		expected any
+	}{
+		{
+			name:     "nil",
+			input:    nil,
+			expected: nil,
+		},
+		{
+			name:     "default policy",
+			input:    MapAny{"zero": 0, "false": false, "empty": "", "num": 1},
+			expected: MapAny{"num": 1},
+		},
+		{
+			name:     "keep zero numbers",
+			input:    map[string]any{"replicas": 0, "ratio": 0.0, "paused": false, "name": ""},
+			policy:   ShrinkPolicy{KeepZeroNumbers: true},
+			expected: MapAny{"replicas": 0, "ratio": 0.0},
+		},
+		{
+			name:     "keep false and empty strings",
+			input:    []any{0, false, "", nil, []any{}},
+			policy:   ShrinkPolicy{KeepFalse: true, KeepEmptyStrings: true},
+			expected: []any{false, ""},
+		},
+		{
+			name: "nested",
+			input: MapAny{
+				"spec": map[string]any{
+					"replicas": 0,
+					"template": MapAny{"paused": false},
+				},
+				"list": []map[string]int{{"a": 0}, {"b": 1}},
+			},
+			policy: ShrinkPolicy{KeepZeroNumbers: true},
+			expected: MapAny{
+				"spec": MapAny{"replicas": 0},
+				"list": []any{MapAny{"a": 0}, MapAny{"b": 1}},
+			},
+		},
+		{
+			name: "max depth",
+			input: MapAny{
+				"a":     "",
+				"b":     MapAny{"c": "", "d": MapAny{"e": ""}},
+				"empty": MapAny{},
+			},
+			policy:   ShrinkPolicy{MaxDepth: 2},
+			expected: MapAny{"b": MapAny{"d": MapAny{"e": ""}}},
+		},
+		{
+			name:     "non-string keys",
+			input:    map[int]any{1: 0, 2: "x", 3: map[string]any{"a": ""}},
+			expected: map[int]any{2: "x"},
+		},
+		{
+			name:     "bytes",
+			input:    MapAny{"b": []byte{0, 1}, "empty": []byte{}},
+			expected: MapAny{"b": []byte{0, 1}},
+		},
+		{
+			name:     "struct with tags",
+			input:    &shrinkSpec{Secret: "s", Started: started},
+			expected: MapAny{"labels": map[string]any(nil), "minimum": 0, "started": started, "version": 0},
+		},
+		{
+			name: "struct with policy",
+			input: []shrinkSpec{
+				{Owner: &shrinkOwner{Name: "n"}, shrinkMeta: shrinkMeta{Version: 2, Comment: "c"}},
+			},
+			policy: ShrinkPolicy{KeepZeroNumbers: true, KeepFalse: true},
+			expected: []any{MapAny{
+				"replicas": 0,
+				"paused":   false,
+				"minimum":  0,
+				"labels":   map[string]any(nil),
+				"owner":    MapAny{"name": "n"},
+				"version":  2,
+				"comment":  "c",
+			}},
+		},
+		{
+			name:     "struct tag name",
+			input:    shrinkOwner{Name: "n"},
+			policy:   ShrinkPolicy{TagName: "yaml"},
+			expected: MapAny{"Name": "n"},
+		},
+		{
+			name:     "zero time",
+			input:    MapAny{"t": time.Time{}, "p": &started},
+			expected: MapAny{"p": started},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, ShrinkWith(tt.input, tt.policy))
+		})
+	}
+
+	assert.Nil(t, MapAny(nil).ShrinkWith(ShrinkPolicy{}))
+	assert.Equal(t, MapAny{"a": false}, MapAny{"a": false, "b": nil}.ShrinkWith(ShrinkPolicy{KeepFalse: true}))
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/effective-security/x/enum"
+	"github.com/effective-security/x/format"
 	"github.com/effective-security/xlog"
 )
 
@@ -231,7 +232,8 @@ func Bool(v any) bool {
 	}
 }
 
-// Time will return the value as Time
+// Time will return the value as Time,
+// see format.TimeParser for supported strings and Unix time units
 func Time(v any) *time.Time {
 	if v == nil {
 		return nil
@@ -246,37 +248,17 @@ func Time(v any) *time.Time {
 		return &tv
 	case *time.Time:
 		return tv
-	case int64:
-		t := time.Unix(tv, 0)
-		return &t
-	case uint64:
-		t := time.Unix(int64(tv), 0)
-		return &t
-	case float64:
-		t := time.Unix(int64(tv), 0)
-		return &t
-	case int:
-		t := time.Unix(int64(tv), 0)
-		return &t
-	case string:
-		if len(tv) > 20 {
-			t, err := time.Parse("2006-01-02T15:04:05.000-0700", tv)
-			if err != nil {
-				return nil
-			}
-			return &t
-		}
-		unix, err := strconv.ParseInt(tv, 10, 64)
-		if err != nil {
-			logger.KV(xlog.DEBUG, "val", v, "err", err.Error())
-			return nil
-		}
-		t := time.Unix(unix, 0)
-		return &t
-	default:
-		logger.KV(xlog.DEBUG, "reason", "unsupported", "val", v, "type", fmt.Sprintf("%T", v))
+	}
+
+	t, err := format.DefaultTimeParser.Parse(v)
+	if err != nil {
+		logger.KV(xlog.DEBUG, "val", v, "err", err.Error())
+		return nil
+	}
+	if t.IsZero() {
 		return nil
 	}
+	return &t
 }
 
 // Int will return the value as int
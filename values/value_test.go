@@ -577,13 +577,14 @@ func TestValues_Time(t *testing.T) {
 	c(o, "str", tPtr(time.Date(2006, time.January, 2, 15, 4, 5, 0, loc)))
 	c(o, "invalid", nil)
 	c(o, "ze", nil)
-	c(o, "int16", nil)
-	c(o, "int32", nil)
-	c(o, "int", tPtr(time.Unix(189898989898, 0)))
-	c(o, "float", tPtr(time.Unix(189898989898, 0)))
+	c(o, "int16", tPtr(time.Unix(20, 0)))
+	c(o, "int32", tPtr(time.Unix(32, 0)))
+	// values above 1e11 are milliseconds
+	c(o, "int", tPtr(time.UnixMilli(189898989898)))
+	c(o, "float", tPtr(time.UnixMilli(189898989898)))
 	c(o, "int64", tPtr(time.Unix(64, 0)))
-	c(o, "uint", nil)
-	c(o, "uint32", nil)
+	c(o, "uint", tPtr(time.Unix(123, 0)))
+	c(o, "uint32", tPtr(time.Unix(132, 0)))
 	c(o, "uint64", tPtr(time.Unix(164, 0)))
 	c(o, "interfaces", tPtr(time.Unix(164, 0)))
 	c(o, "einterface", nil)